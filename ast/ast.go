@@ -3,6 +3,7 @@ package ast
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/polarysfoundation/ryot/token"
@@ -32,6 +33,15 @@ type Expression interface {
 
 type Program struct {
 	Statements []Statement
+	// Comments holds the comments attached to each statement. It is nil unless
+	// the lexer was created with lexer.WithComments.
+	Comments map[Node]*CommentGroup `json:"-"`
+}
+
+// CommentGroup holds the comments the parser attaches to a node.
+type CommentGroup struct {
+	Leading  []token.Token // Comments on the lines right before the node.
+	Trailing []token.Token // Comments after the node on its last line, or at the end of its block.
 }
 
 func (p *Program) TokenLiteral() string {
@@ -46,6 +56,21 @@ func (p *Program) String() string {
 	return "Program"
 }
 
+// TokenOf returns the Token field of a node, which holds its source position.
+// Every node except Program has one.
+func TokenOf(node Node) (token.Token, bool) {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return token.Token{}, false
+	}
+	field := v.Elem().FieldByName("Token")
+	if !field.IsValid() {
+		return token.Token{}, false
+	}
+	tok, ok := field.Interface().(token.Token)
+	return tok, ok
+}
+
 // ---------- Pragma ----------
 
 type PragmaStatement struct {
@@ -57,12 +82,33 @@ func (ps *PragmaStatement) statementNode()       {}
 func (ps *PragmaStatement) TokenLiteral() string { return ps.Token.Literal }
 func (ps *PragmaStatement) String() string       { return "pragma: \"" + ps.Value + "\";" }
 
+// ---------- Import ----------
+
+// ImportStatement brings the classes of another file into the compilation unit:
+// import "./token.ry"; imports all of them, import { IToken } from "./token.ry";
+// only the listed ones.
+type ImportStatement struct {
+	Token token.Token
+	Path  string
+	Names []string
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) String() string {
+	if len(is.Names) == 0 {
+		return "import \"" + is.Path + "\";"
+	}
+	return "import { " + strings.Join(is.Names, ", ") + " } from \"" + is.Path + "\";"
+}
+
 // ---------- Clase ----------
 
 type ClassStatement struct {
 	Token       token.Token
 	Name        string
 	IsInterface bool
+	Parents     []string // Contratos e interfaces heredados, en el orden declarado tras 'is'
 	Body        []Statement
 }
 
@@ -139,7 +185,7 @@ func (v *Value) String() string       { return v.Type }
 type StorageStatement struct {
 	Token  token.Token // Token de tipo 'storage'
 	Name   string
-	Params []Identifier
+	Params []Expression
 	Value  Expression
 }
 
@@ -151,7 +197,7 @@ func (ss *StorageStatement) String() string       { return ss.Name }
 type StorageAccessStatement struct {
 	Token  token.Token // Token de tipo 'storage'
 	Name   string
-	Params []Identifier
+	Params []Expression
 }
 
 func (sas *StorageAccessStatement) statementNode()       {}
@@ -181,6 +227,9 @@ type FuncStatement struct {
 	Public     bool
 	Name       string
 	Params     []Key
+	Modifiers  []ModifierInvocation
+	Override   bool
+	Mutability string // "view", "pure" or "payable" when declared right after the parameters, empty otherwise
 	ReturnType Value
 	Body       []Statement
 }
@@ -189,6 +238,111 @@ func (fs *FuncStatement) statementNode()       {}
 func (fs *FuncStatement) TokenLiteral() string { return fs.Token.Literal }
 func (fs *FuncStatement) String() string       { return fs.Name }
 
+// ---------- Constructor ----------
+
+// ConstructorStatement runs once at deployment time, after the contract
+// variables have been initialized.
+type ConstructorStatement struct {
+	Token  token.Token
+	Params []Key
+	Body   []Statement
+}
+
+func (cs *ConstructorStatement) statementNode()       {}
+func (cs *ConstructorStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ConstructorStatement) String() string       { return "constructor" }
+
+// ---------- Error ----------
+
+// ErrorStatement declares a custom error with typed parameters,
+// e.g. error InsufficientBalance(needed: uint64, available: uint64);
+type ErrorStatement struct {
+	Token  token.Token
+	Name   string
+	Params []Key
+}
+
+func (es *ErrorStatement) statementNode()       {}
+func (es *ErrorStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ErrorStatement) String() string       { return "error " + es.Name }
+
+// RevertStatement aborts execution with a custom error,
+// e.g. revert InsufficientBalance(x, y);
+type RevertStatement struct {
+	Token token.Token
+	Error *ErrorCallExpression
+}
+
+func (rs *RevertStatement) statementNode()       {}
+func (rs *RevertStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *RevertStatement) String() string       { return "revert " + rs.Error.String() }
+
+// ErrorCallExpression instantiates a custom error with its arguments. It is
+// used by revert statements and by check(cond, err: Name(args)).
+type ErrorCallExpression struct {
+	Token     token.Token // The token.IDENT token of the error name
+	Name      string
+	Arguments []Expression
+}
+
+func (ec *ErrorCallExpression) expressionNode()      {}
+func (ec *ErrorCallExpression) TokenLiteral() string { return ec.Token.Literal }
+func (ec *ErrorCallExpression) String() string {
+	var out bytes.Buffer
+	args := []string{}
+	for _, a := range ec.Arguments {
+		args = append(args, a.String())
+	}
+	out.WriteString(ec.Name)
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(")")
+	return out.String()
+}
+
+// ---------- Modifier ----------
+
+// ModifierStatement declares a reusable guard whose body wraps the body of
+// every function it is applied to; the placeholder `_;` marks where the
+// function body is inlined.
+type ModifierStatement struct {
+	Token  token.Token
+	Name   string
+	Params []Key
+	Body   []Statement
+}
+
+func (ms *ModifierStatement) statementNode()       {}
+func (ms *ModifierStatement) TokenLiteral() string { return ms.Token.Literal }
+func (ms *ModifierStatement) String() string       { return "modifier " + ms.Name }
+
+// ModifierInvocation applies a modifier to a function, e.g. onlyOwner or onlyRole(1)
+type ModifierInvocation struct {
+	Token     token.Token
+	Name      string
+	Arguments []Expression
+}
+
+func (mi *ModifierInvocation) String() string {
+	if len(mi.Arguments) == 0 {
+		return mi.Name
+	}
+	args := []string{}
+	for _, a := range mi.Arguments {
+		args = append(args, a.String())
+	}
+	return mi.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// PlaceholderStatement is the `_;` inside a modifier body
+type PlaceholderStatement struct {
+	Token token.Token
+}
+
+func (ps *PlaceholderStatement) statementNode()       {}
+func (ps *PlaceholderStatement) TokenLiteral() string { return ps.Token.Literal }
+func (ps *PlaceholderStatement) String() string       { return "_;" }
+
 // ---------- Otras declaraciones dentro del cuerpo ----------
 
 type ReturnStatement struct {
@@ -283,6 +437,55 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// MemberExpression represents a field access such as block.number
+type MemberExpression struct {
+	Token  token.Token // The token.IDENT token of the object
+	Object Expression
+	Member string
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string       { return me.Object.String() + "." + me.Member }
+
+// ExternalCallExpression calls a function of another contract through an
+// interface declaration, e.g. IToken(addr).transfer(to, amount)
+type ExternalCallExpression struct {
+	Token     token.Token // The token.IDENT token of the interface name
+	Interface string
+	Address   Expression
+	Method    string
+	Arguments []Expression
+}
+
+func (ec *ExternalCallExpression) expressionNode()      {}
+func (ec *ExternalCallExpression) TokenLiteral() string { return ec.Token.Literal }
+func (ec *ExternalCallExpression) String() string {
+	args := []string{}
+	for _, a := range ec.Arguments {
+		args = append(args, a.String())
+	}
+	return ec.Interface + "(" + ec.Address.String() + ")." + ec.Method + "(" + strings.Join(args, ", ") + ")"
+}
+
+// CreateExpression deploys a new instance of another contract of the program
+// and evaluates to its address, e.g. create Vault(owner, 100)
+type CreateExpression struct {
+	Token     token.Token // The token.CREATE token
+	Contract  string
+	Arguments []Expression
+}
+
+func (ce *CreateExpression) expressionNode()      {}
+func (ce *CreateExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CreateExpression) String() string {
+	args := []string{}
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+	return "create " + ce.Contract + "(" + strings.Join(args, ", ") + ")"
+}
+
 // IntegerLiteral represents an integer literal in the AST.
 type IntegerLiteral struct {
 	Token token.Token // The token.INT token
@@ -478,17 +681,28 @@ func (ev *ErrValue) TokenLiteral() string {
 
 func (ev *ErrValue) expressionNode() {}
 
+// Kinds of contract variable. Constants are inlined wherever they are used and
+// immutables are assigned once in the constructor; neither takes a storage slot.
+const (
+	KindConst     = "const"
+	KindImmutable = "immutable"
+)
+
 type VariableStatement struct {
 	Token  token.Token // The type token (e.g., uint64, string)
 	Name   string
 	Value  Expression
 	Public bool
+	Kind   string // KindConst or KindImmutable, empty for variables kept in storage
 }
 
 func (vd *VariableStatement) statementNode()       {}
 func (vd *VariableStatement) TokenLiteral() string { return vd.Token.Literal }
 func (vd *VariableStatement) String() string {
 	var out bytes.Buffer
+	if vd.Kind != "" {
+		out.WriteString(vd.Kind + " ")
+	}
 	out.WriteString(vd.Token.Literal) // Type
 	out.WriteString(" ")
 	out.WriteString(vd.Name) // Variable name
@@ -503,14 +717,31 @@ type VariableStatementNonInitializer struct {
 	Token  token.Token // The type token (e.g., uint64, string)
 	Name   string
 	Public bool
+	Kind   string // KindConst or KindImmutable, empty for variables kept in storage
 }
 
 func (vd *VariableStatementNonInitializer) statementNode()       {}
 func (vd *VariableStatementNonInitializer) TokenLiteral() string { return vd.Token.Literal }
 func (vd *VariableStatementNonInitializer) String() string {
 	var out bytes.Buffer
+	if vd.Kind != "" {
+		out.WriteString(vd.Kind + " ")
+	}
 	out.WriteString(vd.Token.Literal) // Type
 	out.WriteString(" ")
 	out.WriteString(vd.Name) // Variable name
 	return out.String()
 }
+
+// AssignStatement stores a new value in a contract variable: count: count + 1;
+type AssignStatement struct {
+	Token token.Token // The token.IDENT token of the variable
+	Name  string
+	Value Expression
+}
+
+func (as *AssignStatement) statementNode()       {}
+func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssignStatement) String() string {
+	return as.Name + ": " + as.Value.String() + ";"
+}
@@ -0,0 +1,95 @@
+package ast
+
+// Inspect traverses the tree rooted at node in depth-first order, calling f for
+// every node. If f returns false the children of that node are skipped.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Inspect(child, f)
+	}
+}
+
+// children returns the child nodes of node that can hold statements or
+// expressions, in source order.
+func children(node Node) []Node {
+	var nodes []Node
+	add := func(exprs ...Expression) {
+		for _, expr := range exprs {
+			if expr != nil {
+				nodes = append(nodes, expr)
+			}
+		}
+	}
+	addStatements := func(stmts []Statement) {
+		for _, stmt := range stmts {
+			nodes = append(nodes, stmt)
+		}
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		addStatements(n.Statements)
+	case *ClassStatement:
+		addStatements(n.Body)
+	case *FuncStatement:
+		for _, inv := range n.Modifiers {
+			add(inv.Arguments...)
+		}
+		addStatements(n.Body)
+	case *ModifierStatement:
+		addStatements(n.Body)
+	case *ConstructorStatement:
+		addStatements(n.Body)
+	case *VariableStatement:
+		add(n.Value)
+	case *AssignStatement:
+		add(n.Value)
+	case *ExpressionStatement:
+		add(n.Expression)
+	case *ReturnStatement:
+		add(n.Value)
+	case *RevertStatement:
+		if n.Error != nil {
+			add(n.Error)
+		}
+	case *NewStatement:
+		for i := range n.Params {
+			add(&n.Params[i])
+		}
+		add(n.Value)
+	case *DeleteStatement:
+		for i := range n.Params {
+			add(&n.Params[i])
+		}
+	case *ErrLiteral:
+		add(n.Value, n.Return)
+	case *ErrValue:
+		add(n.Value)
+	case *ErrorCallExpression:
+		add(n.Arguments...)
+	case *StorageAccessStatement:
+		add(n.Params...)
+	case *StorageStatement:
+		add(n.Params...)
+		add(n.Value)
+	case *ConstExpression:
+		add(n.Value)
+	case *BinaryExpression:
+		add(n.Left, n.Right)
+	case *CallExpression:
+		add(n.Function)
+		add(n.Arguments...)
+	case *MemberExpression:
+		add(n.Object)
+	case *ExternalCallExpression:
+		add(n.Address)
+		add(n.Arguments...)
+	case *CreateExpression:
+		add(n.Arguments...)
+	case *ArrayLiteral:
+		add(n.Elements...)
+	}
+	return nodes
+}
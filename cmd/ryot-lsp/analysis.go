@@ -0,0 +1,383 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/parser"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// Clases de símbolo.
+const (
+	kindClass       = "class"
+	kindInterface   = "interface"
+	kindFunc        = "func"
+	kindConstructor = "constructor"
+	kindModifier    = "modifier"
+	kindStorage     = "storage"
+	kindVariable    = "variable"
+	kindStruct      = "struct"
+	kindEnum        = "enum"
+	kindError       = "error"
+	kindField       = "field"
+	kindValue       = "value"
+	kindParam       = "param"
+)
+
+// symbol es una declaración de un documento.
+type symbol struct {
+	name     string
+	kind     string
+	detail   string      // Declaración legible, p. ej. "pub func f(a: uint64): bool".
+	doc      string      // Texto de los comentarios /// que la preceden.
+	public   bool        // Las funciones privadas no se ofrecen tras Clase(dirección).
+	uri      string      // Documento que la declara.
+	pos      token.Token // Token del nombre.
+	start    token.Token // Primer token de la declaración.
+	end      token.Token // Último token de la declaración.
+	parents  []string    // Clases base, en las clases.
+	params   []*symbol   // Parámetros, en funciones, constructores y modificadores.
+	children []*symbol   // Miembros de una clase, campos de un struct o valores de un enum.
+}
+
+// contains indica si la declaración incluye la posición pos.
+func (s *symbol) contains(pos token.Token) bool {
+	return !before(pos, s.start) && !before(tokenEnd(s.end), pos)
+}
+
+// document es un archivo .ry analizado.
+type document struct {
+	uri     string
+	path    string
+	text    string
+	lines   []string
+	tokens  []token.Token // Tokens del código, sin comentarios.
+	program *ast.Program  // Puede estar incompleto si el código tiene errores.
+	classes []*symbol
+}
+
+// analyze divide el texto en tokens, lo analiza y extrae sus declaraciones.
+func analyze(uri, path, text string) *document {
+	d := &document{uri: uri, path: path, text: text, lines: strings.Split(text, "\n")}
+
+	l := lexer.New(text)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		d.tokens = append(d.tokens, tok)
+	}
+
+	program, ok := parser.New(lexer.New(text, lexer.WithComments())).ParseProgram().(*ast.Program)
+	if !ok {
+		program = &ast.Program{}
+	}
+	d.program = program
+	for _, stmt := range program.Statements {
+		if class, ok := stmt.(*ast.ClassStatement); ok {
+			d.classes = append(d.classes, d.classSymbol(class))
+		}
+	}
+	return d
+}
+
+// imports devuelve las importaciones del documento.
+func (d *document) imports() []*ast.ImportStatement {
+	var imports []*ast.ImportStatement
+	for _, stmt := range d.program.Statements {
+		if imp, ok := stmt.(*ast.ImportStatement); ok {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+func (d *document) classSymbol(c *ast.ClassStatement) *symbol {
+	s := &symbol{
+		name:    c.Name,
+		kind:    kindClass,
+		detail:  "class contract " + c.Name,
+		public:  true,
+		uri:     d.uri,
+		pos:     d.next(c.Token, c.Name),
+		start:   c.Token,
+		end:     d.end(c.Token),
+		parents: c.Parents,
+		doc:     d.docOf(c),
+	}
+	if c.IsInterface {
+		s.kind, s.detail = kindInterface, "class interface "+c.Name
+	}
+	if len(c.Parents) > 0 {
+		s.detail += " is " + strings.Join(c.Parents, ", ")
+	}
+	for _, stmt := range c.Body {
+		if member := d.memberSymbol(stmt, c.IsInterface); member != nil {
+			s.children = append(s.children, member)
+		}
+	}
+	return s
+}
+
+// memberSymbol devuelve el símbolo de una declaración del cuerpo de una clase.
+func (d *document) memberSymbol(stmt ast.Statement, inInterface bool) *symbol {
+	tok, ok := ast.TokenOf(stmt)
+	if !ok {
+		return nil
+	}
+	s := &symbol{uri: d.uri, start: d.start(tok), end: d.end(tok), doc: d.docOf(stmt), public: true}
+
+	switch n := stmt.(type) {
+	case *ast.FuncStatement:
+		s.name, s.kind, s.public = n.Name, kindFunc, n.Public || inInterface
+		s.detail = visibility(n.Public) + "func " + signature(n.Name, n.Params, "")
+		if n.Mutability != "" {
+			s.detail += " " + n.Mutability
+		}
+		if n.ReturnType.Type != "" {
+			s.detail += ": " + n.ReturnType.Type
+		}
+		s.params = d.paramSymbols(n.Params)
+	case *ast.ConstructorStatement:
+		s.name, s.kind = "constructor", kindConstructor
+		s.detail = signature("constructor", n.Params, "")
+		s.params = d.paramSymbols(n.Params)
+	case *ast.ModifierStatement:
+		s.name, s.kind = n.Name, kindModifier
+		s.detail = "modifier " + signature(n.Name, n.Params, "")
+		s.params = d.paramSymbols(n.Params)
+	case *ast.StorageDeclaration:
+		s.name, s.kind, s.public = n.Name, kindStorage, n.Public
+		s.detail = visibility(n.Public) + "storage " + signature(n.Name, n.Params, n.Value.Type)
+	case *ast.VariableStatement:
+		s.name, s.kind, s.public = n.Name, kindVariable, n.Public
+		s.detail = visibility(n.Public) + n.Token.Literal + " " + n.Name
+		if n.Kind == ast.KindConst { // El valor de una constante es parte de su declaración.
+			s.detail = visibility(n.Public) + n.String()
+		} else if n.Kind != "" {
+			s.detail = visibility(n.Public) + n.Kind + " " + n.Token.Literal + " " + n.Name
+		}
+	case *ast.VariableStatementNonInitializer:
+		s.name, s.kind, s.public = n.Name, kindVariable, n.Public
+		s.detail = visibility(n.Public) + n.String()
+	case *ast.ErrorStatement:
+		s.name, s.kind = n.Name, kindError
+		s.detail = "error " + signature(n.Name, n.Params, "")
+	case *ast.StructStatement:
+		s.name, s.kind = n.Name, kindStruct
+		s.detail = "struct " + n.Name
+		prev := d.next(tok, n.Name)
+		for _, field := range n.Fields {
+			pos := d.next(prev, field.Name)
+			s.children = append(s.children, &symbol{name: field.Name, kind: kindField, detail: field.Name + ": " + field.Type, public: true, uri: d.uri, pos: pos, start: pos, end: pos})
+			prev = pos
+		}
+	case *ast.EnumStatement:
+		s.name, s.kind = n.Name, kindEnum
+		s.detail = "enum " + n.Name
+		prev := d.next(tok, n.Name)
+		for _, value := range n.Values {
+			pos := d.next(prev, value)
+			s.children = append(s.children, &symbol{name: value, kind: kindValue, detail: n.Name + "." + value, public: true, uri: d.uri, pos: pos, start: pos, end: pos})
+			prev = pos
+		}
+	default:
+		return nil
+	}
+
+	s.pos = tok
+	if s.kind != kindConstructor {
+		s.pos = d.next(tok, s.name)
+	}
+	return s
+}
+
+func (d *document) paramSymbols(params []ast.Key) []*symbol {
+	symbols := make([]*symbol, 0, len(params))
+	for _, param := range params {
+		symbols = append(symbols, &symbol{name: param.Name, kind: kindParam, detail: param.Name + ": " + param.Type, public: true, uri: d.uri, pos: param.Token, start: param.Token, end: param.Token})
+	}
+	return symbols
+}
+
+// docOf devuelve el texto de los comentarios /// que preceden a node, en Markdown.
+func (d *document) docOf(node ast.Node) string {
+	group := d.program.Comments[node]
+	if group == nil {
+		return ""
+	}
+	var lines []string
+	for _, c := range group.Leading {
+		if strings.HasPrefix(c.Literal, "///") && !strings.HasPrefix(c.Literal, "////") {
+			lines = append(lines, strings.TrimSpace(c.Literal[len("///"):]))
+		}
+	}
+	return strings.Join(lines, "  \n")
+}
+
+// index devuelve el índice del primer token que no está antes de pos.
+func (d *document) index(pos token.Token) int {
+	return sort.Search(len(d.tokens), func(i int) bool { return !before(d.tokens[i], pos) })
+}
+
+// next devuelve el primer token posterior a from con el texto literal, o from si no hay ninguno.
+func (d *document) next(from token.Token, literal string) token.Token {
+	for i := d.index(from); i < len(d.tokens); i++ {
+		if d.tokens[i].Literal == literal && before(from, d.tokens[i]) {
+			return d.tokens[i]
+		}
+	}
+	return from
+}
+
+// start devuelve el primer token de la declaración que empieza en tok, incluido su pub o priv.
+func (d *document) start(tok token.Token) token.Token {
+	i := d.index(tok)
+	if i > 0 && (d.tokens[i-1].Type == token.PUB || d.tokens[i-1].Type == token.PRIV) {
+		return d.tokens[i-1]
+	}
+	return tok
+}
+
+// end devuelve el último token de la declaración que empieza en tok: su ';' o la
+// llave que cierra su bloque.
+func (d *document) end(tok token.Token) token.Token {
+	depth := 0
+	last := tok
+	for i := d.index(tok); i < len(d.tokens); i++ {
+		switch d.tokens[i].Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			depth--
+			if depth < 0 {
+				return last // El bloque que contiene la declaración se cierra antes.
+			}
+			if depth == 0 {
+				return d.tokens[i]
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				return d.tokens[i]
+			}
+		}
+		last = d.tokens[i]
+	}
+	return last
+}
+
+// tokenAt devuelve el índice del token que está en pos. Si pos está entre dos
+// tokens, prefiere el identificador.
+func (d *document) tokenAt(pos Position) (int, bool) {
+	line, column := d.offset(pos)
+	found := -1
+	for i, tok := range d.tokens {
+		if tok.Line != line || tok.Column > column || column > tok.Column+len(tok.Literal) {
+			continue
+		}
+		if found < 0 || tok.Type == token.IDENT {
+			found = i
+		}
+	}
+	return found, found >= 0
+}
+
+// enclosing devuelve la clase y la función, constructor o modificador que contienen pos.
+func (d *document) enclosing(pos token.Token) (class, fn *symbol) {
+	for _, c := range d.classes {
+		if !c.contains(pos) {
+			continue
+		}
+		for _, member := range c.children {
+			if member.params != nil && member.contains(pos) {
+				return c, member
+			}
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+// position convierte una línea y una columna del lexer (desde 1, en bytes) en
+// una posición LSP.
+func (d *document) position(line, column int) Position {
+	if line < 1 {
+		return Position{}
+	}
+	if line > len(d.lines) {
+		line = len(d.lines)
+	}
+	text := d.lines[line-1]
+	column = min(max(column-1, 0), len(text))
+	return Position{Line: line - 1, Character: utf16Len(text[:column])}
+}
+
+// offset convierte una posición LSP en una línea y una columna del lexer.
+func (d *document) offset(pos Position) (line, column int) {
+	if pos.Line >= len(d.lines) {
+		return len(d.lines), len(d.lines[len(d.lines)-1]) + 1
+	}
+	text := d.lines[pos.Line]
+	units, i := 0, 0
+	for i < len(text) && units < pos.Character {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		units += utf16Len(string(r))
+		i += size
+	}
+	return pos.Line + 1, i + 1
+}
+
+// tokenRange devuelve el rango LSP que ocupa tok.
+func (d *document) tokenRange(tok token.Token) Range {
+	end := tokenEnd(tok)
+	return Range{Start: d.position(tok.Line, tok.Column), End: d.position(end.Line, end.Column)}
+}
+
+// fullRange devuelve el rango de todo el documento.
+func (d *document) fullRange() Range {
+	last := len(d.lines) - 1
+	return Range{End: Position{Line: last, Character: utf16Len(d.lines[last])}}
+}
+
+// tokenEnd devuelve la posición siguiente al último carácter de tok.
+func tokenEnd(tok token.Token) token.Token {
+	return token.Token{Line: tok.Line, Column: tok.Column + len(tok.Literal)}
+}
+
+// before indica si a está antes que b en el código.
+func before(a, b token.Token) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func visibility(public bool) string {
+	if public {
+		return "pub "
+	}
+	return ""
+}
+
+// signature devuelve "nombre(a: tipo, b: tipo): retorno".
+func signature(name string, params []ast.Key, returnType string) string {
+	list := make([]string, 0, len(params))
+	for _, param := range params {
+		list = append(list, param.Name+": "+param.Type)
+	}
+	out := name + "(" + strings.Join(list, ", ") + ")"
+	if returnType != "" {
+		out += ": " + returnType
+	}
+	return out
+}
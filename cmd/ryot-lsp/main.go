@@ -0,0 +1,139 @@
+// Command ryot-lsp es el servidor del Language Server Protocol de Ryot. Habla
+// LSP por la entrada y la salida estándar, así que cualquier editor con un
+// cliente LSP puede usarlo para los archivos .ry:
+//
+//	ryot-lsp [--log archivo]
+//
+// Ofrece diagnósticos del parser y del compilador al abrir y editar un archivo,
+// hover con la declaración y la documentación /// de cada símbolo, ir a la
+// definición de funciones, storages, structs, enums y clases (también en los
+// archivos importados), completado de palabras clave y miembros, los símbolos
+// del documento y el formateo de ryotc fmt.
+//
+// El cliente puede indicar en initializationOptions los directorios donde se
+// buscan las importaciones no relativas: {"searchPath": ["lib"]}.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run atiende una sesión LSP y devuelve el código de salida: 0 si el cliente
+// pidió shutdown antes de exit, 1 en otro caso y 2 si los argumentos son incorrectos.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ryot-lsp", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	logFile := fs.String("log", "", "archivo donde se registran los mensajes del servidor")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	logger := log.New(io.Discard, "ryot-lsp: ", log.LstdFlags)
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintf(stderr, "ryot-lsp: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		logger.SetOutput(f)
+	}
+
+	s := newServer(stdout, logger)
+	return s.serve(bufio.NewReader(stdin))
+}
+
+// message es un mensaje JSON-RPC: una petición si tiene ID y Method, una
+// notificación si solo tiene Method.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response es la respuesta a una petición. Result se omite si hay Error.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+// responseError es un error JSON-RPC.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Códigos de error de JSON-RPC y LSP.
+const (
+	codeParseError           = -32700
+	codeInvalidParams        = -32602
+	codeMethodNotFound       = -32601
+	codeInternalError        = -32603
+	codeServerNotInitialized = -32002
+	codeRequestFailed        = -32803
+)
+
+// notification es un mensaje que el servidor envía sin esperar respuesta.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// readMessage lee un mensaje con su cabecera Content-Length.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("ryot-lsp: Content-Length inválido %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("ryot-lsp: mensaje sin Content-Length")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeMessage escribe v como un mensaje con su cabecera Content-Length.
+func writeMessage(w io.Writer, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
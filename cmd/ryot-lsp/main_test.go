@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const lib = `pragma: "1.0.0";
+
+/// Interfaz de un token.
+class interface IToken {
+    /// Saldo de una cuenta.
+    pub func balance(account: address): uint64;
+}
+`
+
+const wallet = `pragma: "1.0.0";
+
+import "./lib.ry";
+
+class contract Wallet {
+    enum Status: {
+        Active;
+        Closed;
+    }
+
+    /// Saldos guardados.
+    pub storage saved(account: address): uint64;
+
+    /// Consulta un token.
+    /// @param token Dirección del token.
+    pub func holdings(token: address): uint64 {
+        return IToken(token).balance(self()) + saved(token);
+    }
+}
+`
+
+// session envía los mensajes al servidor y devuelve su código de salida y los
+// mensajes que escribió.
+func session(t *testing.T, messages ...interface{}) (int, []map[string]json.RawMessage) {
+	t.Helper()
+	var in, out, stderr bytes.Buffer
+	for _, msg := range messages {
+		if err := writeMessage(&in, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code := run(nil, &in, &out, &stderr)
+
+	var replies []map[string]json.RawMessage
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var reply map[string]json.RawMessage
+		if err := json.Unmarshal(body, &reply); err != nil {
+			t.Fatalf("invalid message %s: %v", body, err)
+		}
+		replies = append(replies, reply)
+	}
+	return code, replies
+}
+
+func request(id int, method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+}
+
+func notify(method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+}
+
+// reply devuelve la respuesta a la petición id, o la notificación method si id es 0.
+func reply(t *testing.T, replies []map[string]json.RawMessage, id int, method string, v interface{}) map[string]json.RawMessage {
+	t.Helper()
+	for _, r := range replies {
+		match := string(r["id"]) == fmt.Sprint(id)
+		if id == 0 {
+			match = string(r["method"]) == `"`+method+`"`
+		}
+		if !match {
+			continue
+		}
+		if v != nil {
+			if err := json.Unmarshal(r["result"], v); err != nil {
+				if err := json.Unmarshal(r["params"], v); err != nil {
+					t.Fatalf("cannot decode %s: %v", r["result"], err)
+				}
+			}
+		}
+		return r
+	}
+	t.Fatalf("no reply for %d %s in %v", id, method, replies)
+	return nil
+}
+
+// at devuelve la posición de la aparición n (desde 0) de substr en text, más offset caracteres.
+func at(text, substr string, n, offset int) Position {
+	i := -1
+	for ; n >= 0; n-- {
+		j := strings.Index(text[i+1:], substr)
+		if j < 0 {
+			panic("not found: " + substr)
+		}
+		i += j + 1
+	}
+	before := text[:i]
+	line := strings.Count(before, "\n")
+	return Position{Line: line, Character: len(before) - strings.LastIndex(before, "\n") - 1 + offset}
+}
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "lib.ry"), []byte(lib), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := pathToURI(filepath.Join(dir, "wallet.ry"))
+	doc := map[string]string{"uri": uri}
+	position := func(p Position) map[string]interface{} {
+		return map[string]interface{}{"textDocument": doc, "position": p}
+	}
+
+	code, replies := session(t,
+		request(1, "initialize", map[string]interface{}{}),
+		notify("initialized", map[string]interface{}{}),
+		notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri, "languageId": "ryot", "version": 1, "text": wallet}}),
+		request(2, "textDocument/hover", position(at(wallet, "saved(token)", 0, 1))),
+		request(3, "textDocument/definition", position(at(wallet, "balance", 0, 2))),
+		request(4, "textDocument/completion", position(at(wallet, ".balance", 0, 1))),
+		request(5, "textDocument/documentSymbol", map[string]interface{}{"textDocument": doc}),
+		request(6, "textDocument/hover", position(at(wallet, "holdings(token", 0, len("holdings(")))),
+		request(7, "textDocument/formatting", map[string]interface{}{"textDocument": doc}),
+		request(8, "textDocument/unknown", map[string]interface{}{}),
+		request(9, "shutdown", nil),
+		notify("exit", nil),
+	)
+	if code != 0 {
+		t.Fatalf("expected exit code 0 after shutdown, got %d", code)
+	}
+
+	var init struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	reply(t, replies, 1, "", &init)
+	for _, capability := range []string{"hoverProvider", "definitionProvider", "completionProvider", "documentSymbolProvider", "documentFormattingProvider"} {
+		if init.Capabilities[capability] == nil {
+			t.Errorf("expected capability %s, got %v", capability, init.Capabilities)
+		}
+	}
+
+	var diagnostics PublishDiagnosticsParams
+	reply(t, replies, 0, "textDocument/publishDiagnostics", &diagnostics)
+	if diagnostics.URI != uri || len(diagnostics.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %+v", diagnostics)
+	}
+
+	var hover Hover
+	reply(t, replies, 2, "", &hover)
+	if want := "```ryot\npub storage saved(account: address): uint64\n```\n\nSaldos guardados."; hover.Contents.Value != want {
+		t.Fatalf("unexpected hover %q", hover.Contents.Value)
+	}
+
+	var locations []Location
+	reply(t, replies, 3, "", &locations)
+	wantRange := Range{Start: at(lib, "balance", 0, 0), End: at(lib, "balance", 0, len("balance"))}
+	if len(locations) != 1 || locations[0].URI != pathToURI(filepath.Join(dir, "lib.ry")) || locations[0].Range != wantRange {
+		t.Fatalf("unexpected definition %+v", locations)
+	}
+
+	var items []CompletionItem
+	reply(t, replies, 4, "", &items)
+	if len(items) != 1 || items[0].Label != "balance" || items[0].Detail != "pub func balance(account: address): uint64" {
+		t.Fatalf("expected the IToken functions after the dot, got %+v", items)
+	}
+
+	var symbols []DocumentSymbol
+	reply(t, replies, 5, "", &symbols)
+	if len(symbols) != 1 || symbols[0].Name != "Wallet" || symbols[0].Kind != SymbolClass {
+		t.Fatalf("unexpected symbols %+v", symbols)
+	}
+	var names []string
+	for _, child := range symbols[0].Children {
+		names = append(names, child.Name)
+	}
+	if strings.Join(names, ",") != "Status,saved,holdings" || len(symbols[0].Children[0].Children) != 2 {
+		t.Fatalf("unexpected members %v", symbols[0].Children)
+	}
+	if symbols[0].Range.Start != (Position{Line: 4}) || symbols[0].Range.End != (Position{Line: 18, Character: 1}) {
+		t.Fatalf("unexpected class range %+v", symbols[0].Range)
+	}
+
+	var paramHover Hover
+	reply(t, replies, 6, "", &paramHover)
+	if paramHover.Contents.Value != "```ryot\ntoken: address\n```" {
+		t.Fatalf("unexpected parameter hover %q", paramHover.Contents.Value)
+	}
+
+	var edits []TextEdit
+	reply(t, replies, 7, "", &edits)
+	if len(edits) != 0 {
+		t.Fatalf("expected no edits for a formatted document, got %+v", edits)
+	}
+
+	if r := reply(t, replies, 8, "", nil); !strings.Contains(string(r["error"]), "-32601") {
+		t.Fatalf("expected method not found, got %s", r["error"])
+	}
+}
+
+func TestSessionDiagnosticsAndFormatting(t *testing.T) {
+	uri := pathToURI(filepath.Join(t.TempDir(), "broken.ry"))
+	broken := "pragma: \"1.0.0\";\nclass contract A {\n  pub func f(): uint64 { revert Missing(); }\n}\n"
+	fixed := "pragma: \"1.0.0\";\nclass contract A {\n  pub func f(): uint64 { return 1; }\n}\n"
+	doc := map[string]string{"uri": uri}
+
+	code, replies := session(t,
+		request(1, "textDocument/hover", map[string]interface{}{"textDocument": doc, "position": Position{}}),
+		request(2, "initialize", map[string]interface{}{}),
+		notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri, "text": broken}}),
+		notify("textDocument/didChange", map[string]interface{}{"textDocument": doc, "contentChanges": []map[string]string{{"text": fixed}}}),
+		request(3, "textDocument/formatting", map[string]interface{}{"textDocument": doc}),
+	)
+	if code != 1 {
+		t.Fatalf("expected exit code 1 without shutdown, got %d", code)
+	}
+
+	if r := reply(t, replies, 1, "", nil); !strings.Contains(string(r["error"]), "-32002") {
+		t.Fatalf("expected server not initialized, got %s", r["error"])
+	}
+
+	var published []PublishDiagnosticsParams
+	for _, r := range replies {
+		if string(r["method"]) == `"textDocument/publishDiagnostics"` {
+			var params PublishDiagnosticsParams
+			json.Unmarshal(r["params"], &params)
+			published = append(published, params)
+		}
+	}
+	if len(published) != 2 || len(published[0].Diagnostics) != 1 || len(published[1].Diagnostics) != 0 {
+		t.Fatalf("expected one diagnostic that goes away after the change, got %+v", published)
+	}
+	diag := published[0].Diagnostics[0]
+	if !strings.Contains(diag.Message, "Missing") || diag.Range.Start.Line != 2 || diag.Severity != SeverityError {
+		t.Fatalf("unexpected diagnostic %+v", diag)
+	}
+
+	var edits []TextEdit
+	reply(t, replies, 3, "", &edits)
+	if len(edits) != 1 || !strings.Contains(edits[0].NewText, "\n    pub func f(): uint64 {\n        return 1;\n    }\n") {
+		t.Fatalf("unexpected formatting edits %+v", edits)
+	}
+}
+
+func TestSessionWarnings(t *testing.T) {
+	uri := pathToURI(filepath.Join(t.TempDir(), "dead.ry"))
+	src := "pragma: \"1.0.0\";\nclass contract A {\n  pub func f(): uint64 {\n    return 1;\n    return 2;\n  }\n}\n"
+
+	_, replies := session(t,
+		request(1, "initialize", map[string]interface{}{}),
+		notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri, "text": src}}),
+	)
+	var diagnostics PublishDiagnosticsParams
+	reply(t, replies, 0, "textDocument/publishDiagnostics", &diagnostics)
+	if len(diagnostics.Diagnostics) != 1 {
+		t.Fatalf("expected one warning, got %+v", diagnostics)
+	}
+	diag := diagnostics.Diagnostics[0]
+	want := Range{Start: Position{Line: 4, Character: 4}, End: Position{Line: 4, Character: 10}}
+	if diag.Severity != SeverityWarning || diag.Range != want || !strings.Contains(diag.Message, "inalcanzable") {
+		t.Fatalf("unexpected warning %+v", diag)
+	}
+}
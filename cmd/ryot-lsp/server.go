@@ -0,0 +1,613 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/compiler"
+	"github.com/polarysfoundation/ryot/format"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// server guarda el estado de una sesión LSP. Atiende los mensajes de uno en uno.
+type server struct {
+	out         io.Writer
+	log         *log.Logger
+	docs        map[string]*document // Documentos abiertos, por URI.
+	searchPath  []string
+	initialized bool
+	shutdown    bool
+}
+
+func newServer(out io.Writer, logger *log.Logger) *server {
+	return &server{out: out, log: logger, docs: make(map[string]*document)}
+}
+
+// serve lee y atiende mensajes hasta recibir exit o el fin de la entrada.
+func (s *server) serve(r *bufio.Reader) int {
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.log.Printf("error al leer: %v", err)
+			}
+			return 1
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		s.handle(msg)
+	}
+}
+
+// handle atiende un mensaje. Un fallo inesperado responde con un error interno
+// en lugar de terminar el servidor.
+func (s *server) handle(msg message) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Printf("%s: %v", msg.Method, r)
+			if msg.ID != nil {
+				s.reply(msg.ID, nil, &responseError{Code: codeInternalError, Message: fmt.Sprint(r)})
+			}
+		}
+	}()
+
+	s.log.Printf("<- %s", msg.Method)
+	if !s.initialized && msg.Method != "initialize" {
+		if msg.ID != nil {
+			s.reply(msg.ID, nil, &responseError{Code: codeServerNotInitialized, Message: "el servidor no se ha inicializado"})
+		}
+		return
+	}
+
+	var result interface{}
+	var err *responseError
+	switch msg.Method {
+	case "initialize":
+		result, err = s.initialize(msg.Params)
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if decode(msg.Params, &params) == nil {
+			s.open(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if decode(msg.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			s.open(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if decode(msg.Params, &params) == nil {
+			delete(s.docs, params.TextDocument.URI)
+			s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+		}
+	case "textDocument/hover":
+		result, err = withPosition(s, msg.Params, s.hover)
+	case "textDocument/definition":
+		result, err = withPosition(s, msg.Params, s.definition)
+	case "textDocument/completion":
+		result, err = withPosition(s, msg.Params, s.completion)
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err = decode(msg.Params, &params); err == nil {
+			result, err = s.documentSymbols(params.TextDocument.URI)
+		}
+	case "textDocument/formatting":
+		var params DocumentFormattingParams
+		if err = decode(msg.Params, &params); err == nil {
+			result, err = s.formatting(params.TextDocument.URI)
+		}
+	default:
+		if msg.ID != nil {
+			err = &responseError{Code: codeMethodNotFound, Message: "método no soportado: " + msg.Method}
+		}
+	}
+
+	if msg.ID != nil {
+		s.reply(msg.ID, result, err)
+	}
+}
+
+// decode lee los parámetros de un mensaje.
+func decode(params json.RawMessage, v interface{}) *responseError {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// withPosition lee los parámetros de una petición sobre una posición de un
+// documento abierto y llama a handler.
+func withPosition[T any](s *server, raw json.RawMessage, handler func(*document, Position) T) (interface{}, *responseError) {
+	var params TextDocumentPositionParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, &responseError{Code: codeRequestFailed, Message: "documento no abierto: " + params.TextDocument.URI}
+	}
+	return handler(d, params.Position), nil
+}
+
+func (s *server) reply(id json.RawMessage, result interface{}, err *responseError) {
+	resp := response{JSONRPC: "2.0", ID: id, Error: err}
+	if id == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	if err == nil {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			data = json.RawMessage("null")
+			resp.Error = &responseError{Code: codeInternalError, Message: marshalErr.Error()}
+		}
+		resp.Result = data
+	}
+	if err := writeMessage(s.out, resp); err != nil {
+		s.log.Printf("error al escribir: %v", err)
+	}
+}
+
+func (s *server) notify(method string, params interface{}) {
+	if err := writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params}); err != nil {
+		s.log.Printf("error al escribir: %v", err)
+	}
+}
+
+func (s *server) initialize(raw json.RawMessage) (interface{}, *responseError) {
+	var params InitializeParams
+	if len(raw) > 0 {
+		if err := decode(raw, &params); err != nil {
+			return nil, err
+		}
+	}
+	s.searchPath = params.InitializationOptions.SearchPath
+	s.initialized = true
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync":           1, // El cliente envía el texto completo en cada cambio.
+			"hoverProvider":              true,
+			"definitionProvider":         true,
+			"completionProvider":         map[string]interface{}{"triggerCharacters": []string{"."}},
+			"documentSymbolProvider":     true,
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]string{"name": "ryot-lsp", "version": compiler.Version},
+	}, nil
+}
+
+// open guarda el nuevo texto de un documento y publica sus diagnósticos.
+func (s *server) open(uri, text string) {
+	d := analyze(uri, uriToPath(uri), text)
+	s.docs[uri] = d
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: s.diagnostics(d)})
+}
+
+// diagnostics compila el documento en memoria y devuelve sus errores. Los errores
+// de un archivo importado se señalan en la importación.
+func (s *server) diagnostics(d *document) []Diagnostic {
+	contracts, err := compiler.CompileWithOptions(d.text, compiler.Options{
+		InMemory:   true,
+		Artifacts:  compiler.ArtifactABI,
+		FS:         overlay{s},
+		Filename:   d.path,
+		SearchPath: s.searchPath,
+	})
+
+	diagnostics := []Diagnostic{}
+	for _, diag := range append(compiler.AsDiagnostics(err), compiler.Warnings(contracts)...) {
+		out := Diagnostic{Severity: SeverityError, Source: "ryot", Message: diag.Message}
+		if diag.Severity == compiler.SeverityWarning {
+			out.Severity = SeverityWarning
+		}
+		switch {
+		case diag.File != "" && filepath.Clean(diag.File) != filepath.Clean(d.path):
+			out.Message = diag.Error()
+			out.Range = s.importRange(d, diag.File)
+		case diag.Line > 0:
+			out.Range = d.diagnosticRange(diag.Line, diag.Column)
+		}
+		diagnostics = append(diagnostics, out)
+	}
+	return diagnostics
+}
+
+// diagnosticRange devuelve el rango del token que empieza en line:column, o de
+// un carácter si no hay ninguno.
+func (d *document) diagnosticRange(line, column int) Range {
+	for _, tok := range d.tokens {
+		if tok.Line == line && tok.Column == column {
+			return d.tokenRange(tok)
+		}
+	}
+	start := d.position(line, column)
+	end := d.position(line, column+1)
+	return Range{Start: start, End: end}
+}
+
+// importRange devuelve el rango de la importación de d que lleva a file, o el
+// principio del documento si no se encuentra.
+func (s *server) importRange(d *document, file string) Range {
+	resolver := s.resolver()
+	for _, imp := range d.imports() {
+		if path, err := resolver.Resolve(filepath.Dir(d.path), imp.Path); err == nil && filepath.Clean(path) == filepath.Clean(file) {
+			return Range{Start: d.position(imp.Token.Line, imp.Token.Column), End: d.position(d.end(imp.Token).Line, d.end(imp.Token).Column+1)}
+		}
+	}
+	return Range{}
+}
+
+func (s *server) resolver() *compiler.Resolver {
+	return &compiler.Resolver{SearchPath: s.searchPath, FS: overlay{s}}
+}
+
+// load devuelve el documento de path: el abierto en el editor o el del disco.
+func (s *server) load(path string) *document {
+	uri := pathToURI(path)
+	if d, ok := s.docs[uri]; ok {
+		return d
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return analyze(uri, path, string(data))
+}
+
+// classes devuelve las clases visibles en d: las suyas y las de los archivos que
+// importa, directa o indirectamente.
+func (s *server) classes(d *document) []*symbol {
+	resolver := s.resolver()
+	seen := make(map[string]bool)
+	var classes []*symbol
+	var visit func(d *document)
+	visit = func(d *document) {
+		if seen[d.path] {
+			return
+		}
+		seen[d.path] = true
+		classes = append(classes, d.classes...)
+		for _, imp := range d.imports() {
+			path, err := resolver.Resolve(filepath.Dir(d.path), imp.Path)
+			if err != nil {
+				continue
+			}
+			if dep := s.load(path); dep != nil {
+				visit(dep)
+			}
+		}
+	}
+	visit(d)
+	return classes
+}
+
+// members devuelve los miembros de class, los propios primero y después los heredados.
+func members(classes []*symbol, class *symbol) []*symbol {
+	seen := make(map[*symbol]bool)
+	var out []*symbol
+	var visit func(c *symbol)
+	visit = func(c *symbol) {
+		if c == nil || seen[c] {
+			return
+		}
+		seen[c] = true
+		out = append(out, c.children...)
+		for _, parent := range c.parents {
+			visit(find(classes, parent))
+		}
+	}
+	visit(class)
+	return out
+}
+
+func find(symbols []*symbol, name string) *symbol {
+	for _, s := range symbols {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// owner devuelve el símbolo cuyos miembros pueden seguir al punto del token dot:
+// la clase de Clase(dirección).miembro o el enum de Enum.valor.
+func (s *server) owner(d *document, dot int, classes []*symbol) *symbol {
+	i := dot - 1
+	if i >= 0 && d.tokens[i].Type == token.RPAREN {
+		depth := 0
+		for ; i >= 0; i-- {
+			if d.tokens[i].Type == token.RPAREN {
+				depth++
+			} else if d.tokens[i].Type == token.LPAREN {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		i--
+	}
+	if i < 0 || d.tokens[i].Type != token.IDENT {
+		return nil
+	}
+	name := d.tokens[i].Literal
+	if class := find(classes, name); class != nil {
+		return class
+	}
+	if class, _ := d.enclosing(d.tokens[i]); class != nil {
+		if member := find(members(classes, class), name); member != nil && member.kind == kindEnum {
+			return member
+		}
+	}
+	return nil
+}
+
+// resolve devuelve la declaración del identificador d.tokens[i].
+func (s *server) resolve(d *document, i int) *symbol {
+	tok := d.tokens[i]
+	if tok.Type != token.IDENT && tok.Type != token.CONSTRUCTOR {
+		return nil
+	}
+	classes := s.classes(d)
+
+	if i > 0 && d.tokens[i-1].Type == token.DOT {
+		owner := s.owner(d, i-1, classes)
+		if owner == nil {
+			return nil
+		}
+		candidates := owner.children
+		if owner.kind == kindClass || owner.kind == kindInterface {
+			candidates = members(classes, owner)
+		}
+		return find(candidates, tok.Literal)
+	}
+
+	class, fn := d.enclosing(tok)
+	if fn != nil {
+		if param := find(fn.params, tok.Literal); param != nil {
+			return param
+		}
+	}
+	if class != nil {
+		if member := find(members(classes, class), tok.Literal); member != nil {
+			return member
+		}
+	}
+	return find(classes, tok.Literal)
+}
+
+func (s *server) hover(d *document, pos Position) *Hover {
+	i, ok := d.tokenAt(pos)
+	if !ok {
+		return nil
+	}
+	sym := s.resolve(d, i)
+	if sym == nil {
+		return nil
+	}
+	value := "```ryot\n" + sym.detail + "\n```"
+	if sym.doc != "" {
+		value += "\n\n" + sym.doc
+	}
+	r := d.tokenRange(d.tokens[i])
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: value}, Range: &r}
+}
+
+func (s *server) definition(d *document, pos Position) []Location {
+	i, ok := d.tokenAt(pos)
+	if !ok {
+		return []Location{}
+	}
+	sym := s.resolve(d, i)
+	if sym == nil {
+		return []Location{}
+	}
+	target := d
+	if sym.uri != d.uri {
+		if target = s.load(uriToPath(sym.uri)); target == nil {
+			return []Location{}
+		}
+	}
+	return []Location{{URI: sym.uri, Range: target.tokenRange(sym.pos)}}
+}
+
+// completion ofrece los miembros de la clase o del enum tras un punto y, en otro
+// caso, las palabras clave, las funciones predefinidas y los símbolos visibles.
+func (s *server) completion(d *document, pos Position) []CompletionItem {
+	line, column := d.offset(pos)
+	classes := s.classes(d)
+	items := []CompletionItem{}
+
+	text := d.lines[line-1][:column-1]
+	prefix := strings.TrimRightFunc(text, func(r rune) bool { return r == '_' || isAlnum(r) })
+	if strings.HasSuffix(prefix, ".") {
+		dot := token.Token{Line: line, Column: len(prefix)}
+		for i, tok := range d.tokens {
+			if tok.Type != token.DOT || tok.Line != dot.Line || tok.Column != dot.Column {
+				continue
+			}
+			owner := s.owner(d, i, classes)
+			if owner == nil {
+				break
+			}
+			candidates := owner.children
+			if owner.kind == kindClass || owner.kind == kindInterface {
+				candidates = nil
+				for _, member := range members(classes, owner) {
+					if member.kind == kindFunc && member.public {
+						candidates = append(candidates, member)
+					}
+				}
+			}
+			for _, sym := range candidates {
+				items = append(items, completionItem(sym))
+			}
+		}
+		return items
+	}
+
+	for _, word := range token.Keywords() {
+		items = append(items, CompletionItem{Label: word, Kind: CompletionKeyword})
+	}
+	for _, name := range token.Builtins() {
+		items = append(items, CompletionItem{Label: name, Kind: CompletionFunction, Detail: "builtin"})
+	}
+	class, fn := d.enclosing(token.Token{Line: line, Column: column})
+	if fn != nil {
+		for _, param := range fn.params {
+			items = append(items, completionItem(param))
+		}
+	}
+	if class != nil {
+		for _, member := range members(classes, class) {
+			if member.kind != kindConstructor {
+				items = append(items, completionItem(member))
+			}
+		}
+	}
+	for _, c := range classes {
+		items = append(items, completionItem(c))
+	}
+	return items
+}
+
+var completionKinds = map[string]int{
+	kindClass:     CompletionClass,
+	kindInterface: CompletionInterface,
+	kindFunc:      CompletionMethod,
+	kindModifier:  CompletionFunction,
+	kindStorage:   CompletionField,
+	kindVariable:  CompletionField,
+	kindStruct:    CompletionStruct,
+	kindEnum:      CompletionEnum,
+	kindError:     CompletionFunction,
+	kindField:     CompletionField,
+	kindValue:     CompletionEnumMember,
+	kindParam:     CompletionVariable,
+}
+
+func completionItem(sym *symbol) CompletionItem {
+	item := CompletionItem{Label: sym.name, Kind: completionKinds[sym.kind], Detail: sym.detail}
+	if sym.doc != "" {
+		item.Documentation = &MarkupContent{Kind: "markdown", Value: sym.doc}
+	}
+	return item
+}
+
+var symbolKinds = map[string]int{
+	kindClass:       SymbolClass,
+	kindInterface:   SymbolInterface,
+	kindFunc:        SymbolMethod,
+	kindConstructor: SymbolConstructor,
+	kindModifier:    SymbolFunction,
+	kindStorage:     SymbolField,
+	kindVariable:    SymbolField,
+	kindStruct:      SymbolStruct,
+	kindEnum:        SymbolEnum,
+	kindError:       SymbolObject,
+	kindField:       SymbolField,
+	kindValue:       SymbolEnumMember,
+}
+
+func (s *server) documentSymbols(uri string) ([]DocumentSymbol, *responseError) {
+	d, ok := s.docs[uri]
+	if !ok {
+		return nil, &responseError{Code: codeRequestFailed, Message: "documento no abierto: " + uri}
+	}
+	var convert func(symbols []*symbol) []DocumentSymbol
+	convert = func(symbols []*symbol) []DocumentSymbol {
+		out := make([]DocumentSymbol, 0, len(symbols))
+		for _, sym := range symbols {
+			end := tokenEnd(sym.end)
+			out = append(out, DocumentSymbol{
+				Name:           sym.name,
+				Detail:         sym.detail,
+				Kind:           symbolKinds[sym.kind],
+				Range:          Range{Start: d.position(sym.start.Line, sym.start.Column), End: d.position(end.Line, end.Column)},
+				SelectionRange: d.tokenRange(sym.pos),
+				Children:       convert(sym.children),
+			})
+		}
+		return out
+	}
+	return convert(d.classes), nil
+}
+
+// formatting devuelve una sola edición con el documento formateado, o ninguna si
+// ya lo está.
+func (s *server) formatting(uri string) ([]TextEdit, *responseError) {
+	d, ok := s.docs[uri]
+	if !ok {
+		return nil, &responseError{Code: codeRequestFailed, Message: "documento no abierto: " + uri}
+	}
+	out, err := format.Source([]byte(d.text))
+	if err != nil {
+		return nil, &responseError{Code: codeRequestFailed, Message: err.Error()}
+	}
+	if string(out) == d.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range: d.fullRange(), NewText: string(out)}}, nil
+}
+
+// overlay es el sistema de archivos del compilador: los documentos abiertos en el
+// editor tienen prioridad sobre el disco. No admite escrituras.
+type overlay struct {
+	s *server
+}
+
+func (o overlay) ReadFile(name string) ([]byte, error) {
+	if d, ok := o.s.docs[pathToURI(name)]; ok {
+		return []byte(d.text), nil
+	}
+	return os.ReadFile(name)
+}
+
+func (o overlay) WriteFile(name string, data []byte) error {
+	return fmt.Errorf("ryot-lsp: no se escriben artefactos")
+}
+
+func (o overlay) MkdirAll(dir string) error {
+	return fmt.Errorf("ryot-lsp: no se escriben artefactos")
+}
+
+// uriToPath convierte una URI file:// en una ruta. Otras URIs se usan tal cual.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI convierte una ruta en una URI file://.
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func isAlnum(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
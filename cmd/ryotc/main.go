@@ -0,0 +1,566 @@
+// Command ryotc es el compilador de línea de comandos de Ryot.
+//
+// Uso:
+//
+//	ryotc build   [--out dir] [--doc] [--format text|json] [-I dir]... archivos...
+//	ryotc check   [--format text|json] [-I dir]... archivos...
+//	ryotc disasm  [--init] [--format text|json] [-I dir]... archivos...
+//	ryotc abi     [--format text|json] [-I dir]... archivos...
+//	ryotc fmt     [-w | --check] archivos...
+//	ryotc lint    [--config archivo] [--format text|json] archivos...
+//	ryotc version [--format text|json]
+//	ryotc --standard-json
+//
+// Los archivos admiten patrones (contracts/*.ry) y "-" lee el código fuente de la
+// entrada estándar. El código de salida es 0 si todo compila, 1 si algún archivo
+// tiene errores y 2 si la invocación es incorrecta. Los avisos, como el código
+// inalcanzable, se muestran en stderr sin cambiar el código de salida.
+//
+// lint lee las reglas activas de --config o, si no se indica, de .ryotlint.json
+// en el directorio actual (véase lint.Config) y termina con 1 si encuentra algo.
+//
+// Con --standard-json, ryotc lee de la entrada estándar una entrada JSON estándar
+// (véase compiler.StandardInput) y escribe la salida JSON en la salida estándar.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/codegen"
+	"github.com/polarysfoundation/ryot/compiler"
+	"github.com/polarysfoundation/ryot/format"
+	"github.com/polarysfoundation/ryot/lint"
+)
+
+// Códigos de salida.
+const (
+	exitOK    = 0 // Todo compiló.
+	exitFail  = 1 // Algún archivo tiene errores.
+	exitUsage = 2 // Invocación incorrecta.
+)
+
+const usage = `Uso: ryotc <comando> [opciones] archivos...
+
+Comandos:
+  build    compila y escribe los artefactos en --out (--doc añade doc.md)
+  check    compila sin escribir nada y muestra los errores
+  disasm   muestra el bytecode desensamblado
+  abi      muestra la ABI de cada contrato
+  fmt      formatea el código fuente (-w reescribe, --check solo comprueba)
+  lint     busca errores probables (--config elige las reglas)
+  version  muestra la versión del compilador
+
+Use "-" como archivo para leer de la entrada estándar.
+Con --standard-json se lee JSON estándar de la entrada estándar y se responde en JSON.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run ejecuta ryotc con los argumentos indicados y devuelve el código de salida.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	cmd := &command{name: args[0], stdin: stdin, stdout: stdout, stderr: stderr}
+	switch cmd.name {
+	case "build", "check", "disasm", "abi":
+		return cmd.compile(args[1:])
+	case "fmt":
+		return cmd.formatFiles(args[1:])
+	case "lint":
+		return cmd.lintFiles(args[1:])
+	case "version":
+		return cmd.version(args[1:])
+	case "--standard-json":
+		return cmd.standardJSON(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "ryotc: comando desconocido %q\n\n%s", cmd.name, usage)
+		return exitUsage
+	}
+}
+
+// command guarda las opciones comunes de una invocación.
+type command struct {
+	name   string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	format     string
+	out        string
+	init       bool
+	doc        bool
+	write      bool
+	check      bool
+	config     string
+	searchPath stringList
+}
+
+// stringList es una opción que puede repetirse.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+// fileResult es el resultado de compilar un archivo.
+type fileResult struct {
+	File      string                `json:"file"`
+	Contracts []contractResult      `json:"contracts,omitempty"`
+	Warnings  []compiler.Diagnostic `json:"warnings,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// contractResult es el resultado de una clase compilada.
+type contractResult struct {
+	Name      string      `json:"name"`
+	Interface bool        `json:"interface,omitempty"`
+	Artifacts []string    `json:"artifacts,omitempty"`
+	ABI       codegen.ABI `json:"abi,omitempty"`
+	Bytecode  []string    `json:"bytecode,omitempty"`
+	Init      []string    `json:"init,omitempty"`
+}
+
+func (c *command) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("ryotc "+c.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	if c.name == "fmt" {
+		c.format = "text"
+		fs.BoolVar(&c.write, "w", false, "reescribe los archivos con el código formateado")
+		fs.BoolVar(&c.check, "check", false, "lista los archivos sin formatear y termina con error si hay alguno")
+		return fs
+	}
+	fs.StringVar(&c.format, "format", "text", "formato de salida: text o json")
+	switch c.name {
+	case "version":
+		return fs
+	case "lint":
+		fs.StringVar(&c.config, "config", "", "archivo de configuración de las reglas (por defecto "+lint.ConfigFile+")")
+		return fs
+	}
+	fs.Var(&c.searchPath, "I", "directorio donde buscar las importaciones (repetible)")
+	switch c.name {
+	case "build":
+		fs.StringVar(&c.out, "out", "artifacts", "directorio de salida de los artefactos")
+		fs.BoolVar(&c.doc, "doc", false, "escribe también la referencia del contrato en Markdown (doc.md)")
+	case "disasm":
+		fs.BoolVar(&c.init, "init", false, "incluye el código de inicialización")
+	}
+	return fs
+}
+
+// parse analiza las opciones y comprueba el formato.
+func (c *command) parse(args []string) ([]string, bool) {
+	fs := c.flags()
+	if err := fs.Parse(args); err != nil {
+		return nil, false
+	}
+	if c.format != "text" && c.format != "json" {
+		fmt.Fprintf(c.stderr, "ryotc: formato desconocido %q: use text o json\n", c.format)
+		return nil, false
+	}
+	return fs.Args(), true
+}
+
+func (c *command) version(args []string) int {
+	if _, ok := c.parse(args); !ok {
+		return exitUsage
+	}
+	rybc := fmt.Sprintf("%d.%d", codegen.RyBCVersionMajor, codegen.RyBCVersionMinor)
+	if c.format == "json" {
+		return c.writeJSON(map[string]string{"version": compiler.Version, "rybc": rybc})
+	}
+	fmt.Fprintf(c.stdout, "ryotc %s (RYBC %s)\n", compiler.Version, rybc)
+	return exitOK
+}
+
+// standardJSON compila la entrada JSON estándar leída de stdin.
+func (c *command) standardJSON(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(c.stderr, "ryotc: --standard-json no admite argumentos\n")
+		return exitUsage
+	}
+	input, err := io.ReadAll(c.stdin)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "ryotc: %v\n", err)
+		return exitFail
+	}
+	output, err := compiler.CompileStandardJSON(input)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "ryotc: %v\n", err)
+		return exitFail
+	}
+	fmt.Fprintln(c.stdout, string(output))
+
+	var result compiler.StandardOutput
+	if err := json.Unmarshal(output, &result); err != nil {
+		return exitFail
+	}
+	for _, diag := range result.Errors {
+		if diag.Severity != compiler.SeverityWarning {
+			return exitFail
+		}
+	}
+	return exitOK
+}
+
+// formatFiles formatea los archivos indicados. Sin opciones escribe el resultado en
+// stdout; con -w reescribe los archivos y con --check solo lista los que cambiarían.
+func (c *command) formatFiles(args []string) int {
+	patterns, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	if c.write && c.check {
+		fmt.Fprintf(c.stderr, "ryotc: -w y --check no pueden usarse juntos\n")
+		return exitUsage
+	}
+	files, err := expand(patterns)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "ryotc: %v\n", err)
+		return exitUsage
+	}
+	if c.write && slices.Contains(files, "-") {
+		fmt.Fprintf(c.stderr, "ryotc: -w no admite la entrada estándar\n")
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, file := range files {
+		name := file
+		var src []byte
+		if file == "-" {
+			name = "<stdin>"
+			src, err = io.ReadAll(c.stdin)
+		} else {
+			src, err = os.ReadFile(file)
+		}
+		if err == nil {
+			var out []byte
+			if out, err = format.Source(src); err == nil {
+				switch {
+				case c.check:
+					if !bytes.Equal(src, out) {
+						fmt.Fprintln(c.stdout, name)
+						code = exitFail
+					}
+				case c.write:
+					if !bytes.Equal(src, out) {
+						err = os.WriteFile(file, out, 0644)
+					}
+				default:
+					c.stdout.Write(out)
+				}
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", name, err)
+			code = exitFail
+		}
+	}
+	return code
+}
+
+// lintResult son los hallazgos del linter en un archivo.
+type lintResult struct {
+	File     string         `json:"file"`
+	Findings []lint.Finding `json:"findings"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// lintFiles analiza los archivos indicados con las reglas de la configuración.
+func (c *command) lintFiles(args []string) int {
+	patterns, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	files, err := expand(patterns)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "ryotc: %v\n", err)
+		return exitUsage
+	}
+	config, err := c.lintConfig()
+	if err != nil {
+		fmt.Fprintf(c.stderr, "ryotc: %v\n", err)
+		return exitUsage
+	}
+
+	code := exitOK
+	results := make([]lintResult, 0, len(files))
+	for _, file := range files {
+		result := lintResult{File: file, Findings: []lint.Finding{}}
+		var src []byte
+		if file == "-" {
+			result.File = "<stdin>"
+			src, err = io.ReadAll(c.stdin)
+		} else {
+			src, err = os.ReadFile(file)
+		}
+		if err == nil {
+			result.Findings, err = lint.Source(src, config)
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		if result.Error != "" || len(result.Findings) > 0 {
+			code = exitFail
+		}
+		if c.format == "text" {
+			if result.Error != "" {
+				fmt.Fprintf(c.stderr, "%s: %s\n", result.File, result.Error)
+			}
+			for _, finding := range result.Findings {
+				fmt.Fprintf(c.stdout, "%s:%s\n", result.File, finding)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if c.format == "json" && c.writeJSON(results) != exitOK {
+		return exitFail
+	}
+	return code
+}
+
+// lintConfig lee la configuración de --config o, si no se indicó, de
+// lint.ConfigFile en el directorio actual si existe.
+func (c *command) lintConfig() (lint.Config, error) {
+	path := c.config
+	if path == "" {
+		if _, err := os.Stat(lint.ConfigFile); err != nil {
+			return lint.Config{}, nil
+		}
+		path = lint.ConfigFile
+	}
+	return lint.LoadConfig(path)
+}
+
+// compile ejecuta build, check, disasm o abi sobre los archivos indicados.
+func (c *command) compile(args []string) int {
+	patterns, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	files, err := expand(patterns)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "ryotc: %v\n", err)
+		return exitUsage
+	}
+
+	code := exitOK
+	results := make([]fileResult, 0, len(files))
+	for _, file := range files {
+		result := c.compileFile(file)
+		if c.format == "text" {
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(c.stderr, "%s:%d:%d: aviso: %s\n", result.File, warning.Line, warning.Column, warning.Message)
+			}
+		}
+		if result.Error != "" {
+			code = exitFail
+			if c.format == "text" {
+				fmt.Fprintf(c.stderr, "%s: %s\n", result.File, result.Error)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if c.format == "json" {
+		if c.writeJSON(results) != exitOK {
+			return exitFail
+		}
+		return code
+	}
+	for _, result := range results {
+		c.writeText(result)
+	}
+	return code
+}
+
+// compileFile compila un archivo, o la entrada estándar si file es "-".
+func (c *command) compileFile(file string) fileResult {
+	result := fileResult{File: file}
+
+	var src []byte
+	var err error
+	opts := compiler.Options{SearchPath: c.searchPath, InMemory: true}
+	if file == "-" {
+		result.File = "<stdin>"
+		src, err = io.ReadAll(c.stdin)
+	} else {
+		opts.Filename = file
+		src, err = os.ReadFile(file)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	switch c.name {
+	case "build":
+		opts.InMemory = false
+		opts.OutputDir = c.out
+		if c.doc {
+			opts.Artifacts = compiler.AllArtifacts | compiler.ArtifactMarkdown
+		}
+	case "disasm":
+		opts.Artifacts = compiler.ArtifactRYC
+		if c.init {
+			opts.Artifacts |= compiler.ArtifactInitRYC
+		}
+	case "abi":
+		opts.Artifacts = compiler.ArtifactABI
+	}
+
+	contracts, err := compiler.CompileWithOptions(string(src), opts)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Warnings = compiler.Warnings(contracts)
+	names := make([]string, 0, len(contracts))
+	for name := range contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		contract := contracts[name]
+		entry := contractResult{Name: name, Interface: contract.IsInterface}
+		switch c.name {
+		case "build":
+			for artifact := range contract.Artifacts {
+				entry.Artifacts = append(entry.Artifacts, filepath.Join(c.out, name, artifact))
+			}
+			sort.Strings(entry.Artifacts)
+		case "abi":
+			entry.ABI = contract.ABI
+		case "disasm":
+			if contract.IsInterface {
+				continue
+			}
+			entry.Bytecode = lines(contract.Artifacts["bytecode.ryc"])
+			if c.init {
+				entry.Init = lines(contract.Artifacts["init.ryc"])
+			}
+		}
+		result.Contracts = append(result.Contracts, entry)
+	}
+	return result
+}
+
+// writeText muestra el resultado de un archivo en formato legible.
+func (c *command) writeText(result fileResult) {
+	if result.Error != "" {
+		return // Ya se mostró en stderr.
+	}
+	switch c.name {
+	case "build", "check":
+		names := make([]string, 0, len(result.Contracts))
+		for _, contract := range result.Contracts {
+			names = append(names, contract.Name)
+		}
+		fmt.Fprintf(c.stdout, "ok   %s: %s\n", result.File, strings.Join(names, ", "))
+		if c.name == "build" {
+			for _, contract := range result.Contracts {
+				for _, artifact := range contract.Artifacts {
+					fmt.Fprintf(c.stdout, "     %s\n", artifact)
+				}
+			}
+		}
+	case "disasm":
+		for _, contract := range result.Contracts {
+			fmt.Fprintln(c.stdout, strings.Join(contract.Bytecode, "\n"))
+			if c.init {
+				fmt.Fprintln(c.stdout, strings.Join(contract.Init, "\n"))
+			}
+		}
+	case "abi":
+		for _, contract := range result.Contracts {
+			fmt.Fprintf(c.stdout, "%s:\n", contract.Name)
+			for _, entry := range contract.ABI {
+				fmt.Fprintf(c.stdout, "  %s\n", describe(entry))
+			}
+		}
+	}
+}
+
+func (c *command) writeJSON(v interface{}) int {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(c.stderr, "ryotc: %v\n", err)
+		return exitFail
+	}
+	return exitOK
+}
+
+// describe devuelve una línea legible con la firma de una entrada de la ABI.
+func describe(entry codegen.ABIFunction) string {
+	inputs := make([]string, 0, len(entry.Inputs))
+	for _, input := range entry.Inputs {
+		if input.Name != "" {
+			inputs = append(inputs, input.Name+": "+input.Type)
+		} else {
+			inputs = append(inputs, input.Type)
+		}
+	}
+	line := entry.Type
+	if entry.Name != "" {
+		line += " " + entry.Name
+	}
+	line += "(" + strings.Join(inputs, ", ") + ")"
+	if len(entry.Outputs) > 0 && entry.Outputs[0].Type != "void" {
+		line += ": " + entry.Outputs[0].Type
+	}
+	if entry.Selector != "" {
+		line += " " + entry.Selector
+	}
+	return line
+}
+
+// expand sustituye los patrones por los archivos que los cumplen.
+func expand(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no se indicó ningún archivo (use \"-\" para la entrada estándar)")
+	}
+	var files []string
+	for _, pattern := range patterns {
+		if pattern == "-" {
+			files = append(files, pattern)
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("patrón inválido %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			if strings.ContainsAny(pattern, "*?[") {
+				return nil, fmt.Errorf("ningún archivo coincide con %q", pattern)
+			}
+			matches = []string{pattern} // El error de lectura se informa al compilarlo.
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func lines(data []byte) []string {
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/polarysfoundation/ryot/compiler"
+)
+
+// ryotc ejecuta el comando con la entrada indicada y devuelve su salida.
+func ryotc(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestBuild(t *testing.T) {
+	out := t.TempDir()
+	code, stdout, stderr := ryotc(t, "", "build", "--out", out, "../../example/factory.ry")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if !strings.Contains(stdout, "Factory, Vault") {
+		t.Fatalf("unexpected output: %s", stdout)
+	}
+	for _, file := range []string{"Factory/bytecode.rybc", "Vault/abi.json", "Vault/userdoc.json", "Vault/devdoc.json"} {
+		if _, err := os.Stat(filepath.Join(out, file)); err != nil {
+			t.Fatalf("expected %s: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "Vault", "doc.md")); err == nil {
+		t.Fatalf("doc.md must only be written with --doc")
+	}
+
+	code, _, stderr = ryotc(t, "", "build", "--doc", "--out", out, "../../example/factory.ry")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	md, err := os.ReadFile(filepath.Join(out, "Vault", "doc.md"))
+	if err != nil || !strings.HasPrefix(string(md), "# Vault\n") {
+		t.Fatalf("expected the Markdown reference with --doc, got %q: %v", md, err)
+	}
+}
+
+func TestCheckGlobAndJSON(t *testing.T) {
+	code, stdout, stderr := ryotc(t, "", "check", "--format", "json", "../../example/[cf]*.ry")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+
+	var results []fileResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if len(results) != 3 { // constructor.ry, context.ry y factory.ry
+		t.Fatalf("expected 3 files, got %d", len(results))
+	}
+	if _, err := os.Stat("artifacts"); err == nil {
+		t.Fatalf("check must not write artifacts")
+	}
+}
+
+func TestCheckDiagnostics(t *testing.T) {
+	src := `pragma: "1.0.0";
+	class contract A { pub func f(): uint64 { revert Missing(); } }
+	`
+	code, _, stderr := ryotc(t, src, "check", "-")
+	if code != exitFail {
+		t.Fatalf("expected exit code %d, got %d", exitFail, code)
+	}
+	if !strings.HasPrefix(stderr, "<stdin>: ") {
+		t.Fatalf("expected the diagnostic on stderr, got %q", stderr)
+	}
+}
+
+func TestDisasmAndABIFromStdin(t *testing.T) {
+	src := `pragma: "1.0.0";
+	class contract Counter {
+		pub func get(id: uint64): uint64 {
+			return id;
+		}
+	}
+	`
+	code, stdout, _ := ryotc(t, src, "disasm", "--init", "-")
+	if code != exitOK || !strings.Contains(stdout, "FUNC       get -> uint64") || !strings.Contains(stdout, "DEPLOY") {
+		t.Fatalf("unexpected disassembly (%d): %s", code, stdout)
+	}
+
+	code, stdout, _ = ryotc(t, src, "abi", "-")
+	if code != exitOK || !strings.Contains(stdout, "function get(uint64): uint64 0x") {
+		t.Fatalf("unexpected ABI (%d): %s", code, stdout)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := map[string][]string{
+		"no command":      {},
+		"unknown command": {"link"},
+		"no files":        {"build"},
+		"bad format":      {"check", "--format", "yaml", "-"},
+		"no glob matches": {"check", "missing/*.ry"},
+	}
+	for name, args := range tests {
+		if code, _, _ := ryotc(t, "", args...); code != exitUsage {
+			t.Fatalf("%s: expected exit code %d, got %d", name, exitUsage, code)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	code, stdout, _ := ryotc(t, "", "version")
+	if code != exitOK || !strings.HasPrefix(stdout, "ryotc 1.0.0 (RYBC 1.0)") {
+		t.Fatalf("unexpected version output (%d): %s", code, stdout)
+	}
+}
+
+func TestStandardJSON(t *testing.T) {
+	input := `{
+		"language": "Ryot",
+		"sources": {"counter.ry": {"content": "pragma: \"1.0.0\";\nclass contract Counter {\n  pub func get(id: uint64): uint64 { return id; }\n}"}},
+		"settings": {"outputSelection": {"*": ["abi", "bytecode"]}}
+	}`
+	code, stdout, stderr := ryotc(t, input, "--standard-json")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	var out compiler.StandardOutput
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("expected JSON on stdout: %v\n%s", err, stdout)
+	}
+	counter := out.Contracts["counter.ry"]["Counter"]
+	if len(counter.ABI) != 1 || counter.Bytecode == "" || counter.RYC != "" {
+		t.Fatalf("expected the selected outputs for Counter, got %+v", counter)
+	}
+
+	code, stdout, _ = ryotc(t, `{"sources": {"bad.ry": {"content": "pragma: \"1.0.0\";\nclass contract {"}}}`, "--standard-json")
+	if code != exitFail {
+		t.Fatalf("expected exit code %d, got %d", exitFail, code)
+	}
+	out = compiler.StandardOutput{}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Errors) == 0 || out.Errors[0].File != "bad.ry" || out.Errors[0].Line != 2 {
+		t.Fatalf("expected a positioned diagnostic for bad.ry, got %+v", out.Errors)
+	}
+}
+
+func TestFmt(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.ry")
+	src := "pragma: \"1.0.0\";\nclass contract A {\n\t\tpub func f(): uint64 { return (1 + 2); } // three\n}"
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := "pragma: \"1.0.0\";\n\nclass contract A {\n    pub func f(): uint64 {\n        return 1 + 2;\n    } // three\n}\n"
+
+	code, stdout, _ := ryotc(t, "", "fmt", "--check", file)
+	if code != exitFail || strings.TrimSpace(stdout) != file {
+		t.Fatalf("expected --check to list %s and fail, got %d %q", file, code, stdout)
+	}
+
+	code, stdout, stderr := ryotc(t, "", "fmt", file)
+	if code != exitOK || stdout != want {
+		t.Fatalf("expected the formatted source on stdout, got %d %q %s", code, stdout, stderr)
+	}
+
+	if code, _, stderr := ryotc(t, "", "fmt", "-w", file); code != exitOK {
+		t.Fatalf("expected -w to succeed, got %d: %s", code, stderr)
+	}
+	if got, _ := os.ReadFile(file); string(got) != want {
+		t.Fatalf("expected -w to rewrite the file, got %q", got)
+	}
+	if code, stdout, _ := ryotc(t, "", "fmt", "--check", file); code != exitOK || stdout != "" {
+		t.Fatalf("expected a formatted file to pass --check, got %d %q", code, stdout)
+	}
+
+	if code, _, _ := ryotc(t, src, "fmt", "-w", "-"); code != exitUsage {
+		t.Fatalf("expected -w with stdin to be a usage error, got %d", code)
+	}
+	if code, _, stderr := ryotc(t, "pragma: \"1.0.0\"; class contract A { pub func f(a uint64): uint64 { } }", "fmt", "-"); code != exitFail || !strings.HasPrefix(stderr, "<stdin>: ") {
+		t.Fatalf("expected a syntax error for stdin, got %d %q", code, stderr)
+	}
+}
+
+func TestLint(t *testing.T) {
+	code, stdout, stderr := ryotc(t, "", "lint", "../../example/storage.ry")
+	if code != exitFail {
+		t.Fatalf("expected exit code %d, got %d: %s", exitFail, code, stderr)
+	}
+	if want := "../../example/storage.ry:17:9: la función pública 'deleteCount' modifica el estado sin comprobar caller() (missing-access-check)\n"; !strings.Contains(stdout, want) {
+		t.Fatalf("expected %q, got %q", want, stdout)
+	}
+
+	config := filepath.Join(t.TempDir(), "lint.json")
+	if err := os.WriteFile(config, []byte(`{"rules": {"missing-access-check": false}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr = ryotc(t, "", "lint", "--config", config, "--format", "json", "../../example/storage.ry")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d with the rule disabled, got %d: %s", exitOK, code, stderr)
+	}
+	var results []lintResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil || len(results) != 1 || len(results[0].Findings) != 0 {
+		t.Fatalf("unexpected JSON output %s: %v", stdout, err)
+	}
+
+	if err := os.WriteFile(config, []byte(`{"rules": {"unknown": false}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := ryotc(t, "", "lint", "--config", config, "-"); code != exitUsage || !strings.Contains(stderr, `regla desconocida "unknown"`) {
+		t.Fatalf("expected a usage error for an unknown rule, got %d: %s", code, stderr)
+	}
+
+	if code, _, stderr := ryotc(t, "pragma: \"1.0.0\";\nclass contract A {\n  pub func f(: void {}\n}\n", "lint", "-"); code != exitFail || !strings.HasPrefix(stderr, "<stdin>: lint: ") {
+		t.Fatalf("expected the syntax error on stderr, got %d: %q", code, stderr)
+	}
+}
+
+func TestCheckWarnings(t *testing.T) {
+	src := "pragma: \"1.0.0\";\nclass contract A {\n  pub func f(): uint64 {\n    return 1;\n    return 2;\n  }\n}\n"
+	code, stdout, stderr := ryotc(t, src, "check", "-")
+	if code != exitOK || !strings.HasPrefix(stdout, "ok   <stdin>: A") {
+		t.Fatalf("warnings must not fail the build, got %d: %s%s", code, stdout, stderr)
+	}
+	if want := "<stdin>:5:5: aviso: flow: código inalcanzable después de return\n"; stderr != want {
+		t.Fatalf("expected %q, got %q", want, stderr)
+	}
+
+	input, _ := json.Marshal(compiler.StandardInput{Sources: map[string]compiler.StandardSource{"a.ry": {Content: src}}})
+	code, stdout, _ = ryotc(t, string(input), "--standard-json")
+	if code != exitOK || !strings.Contains(stdout, `"severity": "warning"`) {
+		t.Fatalf("expected a warning and exit code %d, got %d: %s", exitOK, code, stdout)
+	}
+
+	code, _, stderr = ryotc(t, "pragma: \"1.0.0\";\nclass contract A {\n  pub func f(): uint64 {\n  }\n}\n", "check", "-")
+	if code != exitFail || !strings.Contains(stderr, "3:7: flow: la función 'f' devuelve uint64 pero puede terminar sin return") {
+		t.Fatalf("expected the missing return error, got %d: %s", code, stderr)
+	}
+}
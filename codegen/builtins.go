@@ -0,0 +1,196 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// builtin describe una función predefinida del lenguaje: los tipos de sus
+// parámetros, el tipo del resultado y el opcode al que se compila.
+type builtin struct {
+	params   []string
+	result   string
+	opcode   Opcode
+	variadic bool // Acepta cualquier número de argumentos codificables.
+}
+
+// builtins contiene la firma de las funciones de contexto de la blockchain y de
+// hashing. Los nombres de las funciones predefinidas los define token.
+var builtins = map[string]builtin{
+	"caller":       {params: nil, result: "address", opcode: OpCaller},
+	"self":         {params: nil, result: "address", opcode: OpAddress},
+	"balanceOf":    {params: []string{"address"}, result: "uint64", opcode: OpBalance},
+	"pm256":        {result: "hash", opcode: OpHash, variadic: true},
+	"selfdestruct": {params: []string{"address"}, result: "void", opcode: OpSelfDestruct},
+	"value":        {params: nil, result: "uint64", opcode: OpCallValue},
+	"transfer":     {params: []string{"address", "uint64"}, result: "void", opcode: OpTransfer},
+}
+
+// Cada función predefinida que reconoce el parser debe tener una firma, y solo ellas.
+func init() {
+	for _, name := range token.Builtins() {
+		if _, ok := builtins[name]; !ok {
+			panic(fmt.Sprintf("codegen: la función predefinida '%s' no tiene firma", name))
+		}
+	}
+	if len(builtins) != len(token.Builtins()) {
+		panic("codegen: hay firmas de funciones que token no declara como predefinidas")
+	}
+}
+
+// lookupBuiltin devuelve la función predefinida name. Una función o un storage
+// del contrato con el mismo nombre tiene prioridad sobre ella.
+func (g *Generator) lookupBuiltin(name string) (builtin, bool) {
+	if _, declared := g.mutability[name]; declared {
+		return builtin{}, false
+	}
+	if _, declared := g.storages[name]; declared {
+		return builtin{}, false
+	}
+	b, ok := builtins[name]
+	return b, ok
+}
+
+// blockFields contiene los campos accesibles mediante block.<campo>.
+var blockFields = map[string]builtin{
+	"number":    {result: "uint64", opcode: OpBlockNumber},
+	"timestamp": {result: "uint64", opcode: OpTimestamp},
+}
+
+// generateBuiltin comprueba los argumentos de una función predefinida y emite su opcode.
+func (g *Generator) generateBuiltin(name string, b builtin, args []ast.Expression) error {
+	if b.variadic {
+		return g.generateHash(name, b, args)
+	}
+	if name == "value" && !g.acceptsValue() {
+		return fmt.Errorf("codegen: value() solo puede usarse en funciones payable o priv")
+	}
+	if len(args) != len(b.params) {
+		return fmt.Errorf("codegen: '%s' espera %d argumentos, recibió %d", name, len(b.params), len(args))
+	}
+	for i, arg := range args {
+		if argType := g.typeOf(arg); argType != "" && argType != b.params[i] {
+			return fmt.Errorf("codegen: el argumento %d de '%s' debe ser %s, recibió %s", i+1, name, b.params[i], argType)
+		}
+		if err := g.Generate(arg); err != nil {
+			return err
+		}
+	}
+	g.emit(b.opcode)
+	return nil
+}
+
+// acceptsValue indica si la función actual puede leer value(): las funciones
+// pub solo reciben valor si son payable y las priv heredan el de quien las llama.
+func (g *Generator) acceptsValue() bool {
+	if g.currentFunc == nil {
+		return false
+	}
+	return g.currentFunc.Visibility != "public" || g.currentFunc.StateMut == MutabilityPayable
+}
+
+// generateValueGuard emite en la entrada externa de una función pub que no es
+// payable la comprobación que revierte la llamada si transfiere moneda nativa,
+// equivalente a check(value() == 0, err: "...").
+func (g *Generator) generateValueGuard(name string) {
+	accepted := g.newLabel()
+	g.emit(OpCheck)
+	g.emit(OpCallValue)
+	g.emit(OpConst, uint64(0))
+	g.emit(OpEq)
+	g.emit(OpCheckEnd)
+	g.emit(OpJumpI, uint64(accepted))
+	g.emit(OpConst, fmt.Sprintf("la función '%s' no es payable", name))
+	g.emit(OpErr)
+	g.emit(OpLabel, uint64(accepted))
+}
+
+// generateHash genera pm256(...). Si todos los argumentos son constantes el hash se
+// calcula en compilación; si no, se evalúan los argumentos y HASH los codifica en ejecución.
+func (g *Generator) generateHash(name string, b builtin, args []ast.Expression) error {
+	if len(args) == 0 {
+		return fmt.Errorf("codegen: '%s' espera al menos un argumento", name)
+	}
+	for i, arg := range args {
+		if _, ok := arg.(*ast.ArrayLiteral); ok {
+			return fmt.Errorf("codegen: el argumento %d de '%s' no es codificable", i+1, name)
+		}
+		if argType := g.typeOf(arg); argType != "" && !encodable(argType) {
+			return fmt.Errorf("codegen: el argumento %d de '%s' no es codificable: %s", i+1, name, argType)
+		}
+	}
+
+	if digest, ok := foldPM256(args); ok {
+		g.emit(OpHash, digest)
+		return nil
+	}
+
+	for _, arg := range args {
+		if err := g.Generate(arg); err != nil {
+			return err
+		}
+	}
+	g.emit(b.opcode, uint64(len(args)))
+	return nil
+}
+
+// generateMember genera el acceso a un campo como block.number.
+func (g *Generator) generateMember(n *ast.MemberExpression) error {
+	if object, ok := n.Object.(*ast.Identifier); ok && object.Value == "block" {
+		if field, ok := blockFields[n.Member]; ok {
+			g.emit(field.opcode)
+			return nil
+		}
+	}
+	return fmt.Errorf("codegen: miembro desconocido '%s'", n.String())
+}
+
+// typeOf infiere el tipo de una expresión. Devuelve "" cuando no puede determinarlo,
+// en cuyo caso las comprobaciones de tipos se omiten.
+func (g *Generator) typeOf(expr ast.Expression) string {
+	switch n := expr.(type) {
+	case *ast.IntegerLiteral:
+		return "uint64"
+	case *ast.BooleanLiteral:
+		return "bool"
+	case *ast.StringLiteral:
+		return "string"
+	case *ast.AddressExpression:
+		return "address"
+	case *ast.HashLiteral:
+		return "hash"
+	case *ast.Identifier:
+		if t, ok := g.scope[g.localName(n.Value)]; ok {
+			return t
+		}
+		return g.variables[n.Value]
+	case *ast.StorageAccessStatement:
+		if decl, ok := g.storages[n.Name]; ok {
+			return decl.Value.Type
+		}
+	case *ast.CallExpression:
+		if b, ok := g.lookupBuiltin(n.Function.String()); ok {
+			return b.result
+		}
+	case *ast.MemberExpression:
+		if field, ok := blockFields[n.Member]; ok && n.Object.String() == "block" {
+			return field.result
+		}
+	case *ast.CreateExpression:
+		return "address"
+	case *ast.ExternalCallExpression:
+		if fn, err := g.interfaceFunction(n); err == nil && fn.ReturnType.Type != "void" {
+			return fn.ReturnType.Type
+		}
+	case *ast.BinaryExpression:
+		switch n.Operator {
+		case "+", "-", "*", "/", "%":
+			return "uint64"
+		default:
+			return "bool"
+		}
+	}
+	return ""
+}
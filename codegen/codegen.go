@@ -3,11 +3,13 @@ package codegen
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"strings"
 
 	"github.com/polarysfoundation/ryot/ast"
 	"github.com/polarysfoundation/ryot/token"
+	"github.com/polarysfoundation/ryot/version"
 )
 
 const (
@@ -15,25 +17,44 @@ const (
 	zeroHash     = "0x0000000000000000000000000000000000000000000000000000000000000000" // Zero hash
 )
 
-// Constantes para los números mágicos y la versión del bytecode.
+// Constantes para los números mágicos y la versión del bytecode, que es la del compilador.
 const (
 	RyBCMagicNumber  = "\x52\x59\x42\x43" // RYBC
-	RyBCVersionMajor = 0x01
-	RyBCVersionMinor = 0x00
+	RyBCVersionMajor = version.Major
+	RyBCVersionMinor = version.Minor
 )
 
 // Generator es el encargado de transformar el AST en instrucciones de bytecode
 // y generar la ABI del contrato.
 type Generator struct {
-	instructions []Instruction // Lista de instrucciones generadas.
+	instructions []Instruction // Lista de instrucciones generadas (código de runtime).
+	initCode     []Instruction // Código de inicialización que se ejecuta una sola vez al desplegar.
 	contractName string        // Nombre del contrato actual.
 	abi          ABI           // Interfaz Binaria de Aplicación (ABI) del contrato.
 	currentFunc  *ABIFunction  // Puntero a la función ABI actual que se está procesando.
 	labelCounter int
+	errors       map[string]*ast.ErrorStatement     // Errores personalizados declarados en el contrato actual.
+	modifiers    map[string]*ast.ModifierStatement  // Modificadores declarados en el contrato actual.
+	storages     map[string]*ast.StorageDeclaration // Almacenamientos declarados en el contrato actual.
+	variables    map[string]string                  // Tipos de las variables del contrato actual, incluidas constantes e inmutables.
+	constants    map[string]ast.Expression          // Valor de cada constante del contrato actual, que se inserta donde se usa.
+	immutables   map[string]bool                    // Inmutables del contrato actual.
+	assigned     map[string]bool                    // Inmutables ya asignados; nil fuera del código de inicialización.
+	scope        map[string]string                  // Tipos de los parámetros y constantes locales de la función actual.
+	modifier     *modifierScope                     // Nombres locales del modificador que se está insertando; nil fuera de él.
+	mutability   map[string]string                  // Mutabilidad de cada función del contrato actual (véase inferMutability).
+	classes      map[string]*ast.ClassStatement     // Clases declaradas en el programa, tal como se escribieron.
+	interfaces   map[string]*ast.ClassStatement     // Interfaces declaradas en el programa, por nombre.
+	interfaceABI map[string]ABI                     // ABI de cada interfaz; las interfaces no generan bytecode.
+	contracts    map[string]*ast.ClassStatement     // Contratos con sus miembros heredados, disponibles para create.
+	embeds       []string                           // Contratos que crea el contrato actual y que se incrustan en su código.
+	creating     []string                           // Contratos que están incrustando al actual, para detectar creaciones circulares.
+	outputs      []*Contract                        // Código generado por cada clase, en orden de declaración.
 }
 
 // ABIType representa un tipo de dato en la ABI.
 type ABIType struct {
+	Name string `json:"name,omitempty"`
 	Type string `json:"type"`
 }
 
@@ -45,6 +66,7 @@ type ABIFunction struct {
 	Type       string    `json:"type"`                      // Tipo de elemento ABI (e.g., "function", "constructor").
 	StateMut   string    `json:"stateMutability,omitempty"` // Mutabilidad del estado (e.g., "pure", "view", "nonpayable", "payable").
 	Visibility string    `json:"visibility,omitempty"`      // Visibilidad de la función (e.g., "public", "private").
+	Selector   string    `json:"selector,omitempty"`        // Selector de 4 bytes (e.g., errores personalizados).
 }
 
 // Nuevo método para generar etiquetas
@@ -60,7 +82,17 @@ type ABI []ABIFunction
 func New() *Generator {
 	return &Generator{
 		instructions: make([]Instruction, 0),
+		initCode:     make([]Instruction, 0),
 		abi:          make(ABI, 0),
+		errors:       make(map[string]*ast.ErrorStatement),
+		modifiers:    make(map[string]*ast.ModifierStatement),
+		storages:     make(map[string]*ast.StorageDeclaration),
+		variables:    make(map[string]string),
+		scope:        make(map[string]string),
+		classes:      make(map[string]*ast.ClassStatement),
+		interfaces:   make(map[string]*ast.ClassStatement),
+		interfaceABI: make(map[string]ABI),
+		contracts:    make(map[string]*ast.ClassStatement),
 	}
 }
 
@@ -69,6 +101,21 @@ func (g *Generator) GetInstructions() []Instruction {
 	return g.instructions
 }
 
+// GetInitInstructions devuelve el código de inicialización (variables y constructor).
+func (g *Generator) GetInitInstructions() []Instruction {
+	return g.initCode
+}
+
+// GetInterfaceABIs devuelve la ABI de cada interfaz declarada, por nombre.
+func (g *Generator) GetInterfaceABIs() map[string]ABI {
+	return g.interfaceABI
+}
+
+// GetContracts devuelve el código generado por cada clase del programa, en orden de declaración.
+func (g *Generator) GetContracts() []*Contract {
+	return g.outputs
+}
+
 // GetABI devuelve la ABI generada para el contrato.
 func (g *Generator) GetABI() ABI {
 	return g.abi
@@ -125,8 +172,15 @@ func (g *Generator) formatInstruction(op Opcode, args ...interface{}) string {
 		}
 		return "CONST"
 	case OpAddress:
+		if len(args) == 0 {
+			return "ADDRESS    self" // Dirección del propio contrato.
+		}
 		return fmt.Sprintf("ADDRESS    %v", args[0])
 	case OpHash:
+		// Con un número de argumentos calcula pm256 en ejecución; con un string carga un hash literal.
+		if n, ok := args[0].(uint64); ok {
+			return fmt.Sprintf("HASH       pm256 (%d args)", n)
+		}
 		return fmt.Sprintf("HASH       %v", args[0])
 	case OpArray:
 		return fmt.Sprintf("ARRAY      [%d elements]", args[0])
@@ -145,6 +199,23 @@ func (g *Generator) formatInstruction(op Opcode, args ...interface{}) string {
 		return fmt.Sprintf("CALL       %v (%d args)", args[0], args[1])
 	case OpReturn:
 		return "RETURN" // Return ya no necesita un argumento 'raw' adicional si se emite directamente
+	case OpExtCall:
+		// Selector de la función externa, número de argumentos y tipo del valor devuelto.
+		sig := fmt.Sprintf("EXTCALL    %v (%d args)", args[0], args[1])
+		if ret, ok := args[2].(string); ok && ret != "" && ret != "void" {
+			sig += " -> " + ret
+		}
+		return sig
+	case OpCreate:
+		// Contrato incrustado que se despliega y número de argumentos de su constructor.
+		return fmt.Sprintf("CREATE     %v (%d args)", args[0], args[1])
+	case OpEmbed:
+		return fmt.Sprintf("EMBED      %v", args[0])
+	case OpEntry:
+		return fmt.Sprintf("ENTRY      %v", args[0])
+	case OpRevert:
+		// Selector del error personalizado y número de argumentos que se codifican tras él.
+		return fmt.Sprintf("REVERT     %v (%d args)", args[0], args[1])
 	// Añadir más casos según sea necesario para otras opcodes que requieran formato específico en 'Raw'.
 	default:
 		// Para la mayoría de los opcodes, solo el nombre es suficiente para el 'Raw'
@@ -153,15 +224,48 @@ func (g *Generator) formatInstruction(op Opcode, args ...interface{}) string {
 }
 
 // Generate recorre el árbol de sintaxis abstracta (AST) y genera las instrucciones
-// de bytecode correspondientes. Retorna las instrucciones generadas y un error si ocurre.
+// de bytecode correspondientes. Los errores devueltos son *Error con la posición
+// del nodo más interno que falló, cuando se conoce.
 func (g *Generator) Generate(node ast.Node) error {
+	if err := g.generate(node); err != nil {
+		return g.withPosition(err, node)
+	}
+	return nil
+}
+
+func (g *Generator) generate(node ast.Node) error {
 	if node == nil {
 		return fmt.Errorf("codegen: el nodo AST es nulo")
 	}
 
 	switch n := node.(type) {
 	case *ast.Program:
+		g.classes = make(map[string]*ast.ClassStatement)
+		g.interfaces = make(map[string]*ast.ClassStatement)
 		for _, stmt := range n.Statements {
+			if class, ok := stmt.(*ast.ClassStatement); ok {
+				g.classes[class.Name] = class
+			}
+		}
+		// Las clases se generan con sus miembros heredados ya resueltos.
+		statements := make([]ast.Statement, 0, len(n.Statements))
+		for _, stmt := range n.Statements {
+			if class, ok := stmt.(*ast.ClassStatement); ok {
+				g.contractName = class.Name
+				flat, err := g.flatten(class)
+				if err != nil {
+					return err
+				}
+				if flat.IsInterface {
+					g.interfaces[flat.Name] = flat
+				} else {
+					g.contracts[flat.Name] = flat
+				}
+				stmt = flat
+			}
+			statements = append(statements, stmt)
+		}
+		for _, stmt := range statements {
 			if err := g.Generate(stmt); err != nil {
 				return err
 			}
@@ -170,13 +274,51 @@ func (g *Generator) Generate(node ast.Node) error {
 		g.emit(OpMeta, n.Value)
 	case *ast.ClassStatement:
 		g.contractName = n.Name
+		if n.IsInterface {
+			return g.generateInterface(n)
+		}
+		output := &Contract{Name: n.Name}
+		initStart, runtimeStart, abiStart := len(g.initCode), len(g.instructions), len(g.abi)
+		if err := g.collectDeclarations(n); err != nil {
+			return err
+		}
+		if err := g.checkConstants(n); err != nil {
+			return err
+		}
+		if err := g.inferMutability(n); err != nil {
+			return err
+		}
+		if err := g.generateInit(n); err != nil {
+			return err
+		}
 		g.emit(OpContract, n.Name)
+		g.embeds = nil
 		for _, stmt := range n.Body {
+			switch stmt.(type) {
+			case *ast.VariableStatement, *ast.VariableStatementNonInitializer, *ast.ConstructorStatement:
+				continue // Forman parte del código de inicialización.
+			}
 			if err := g.Generate(stmt); err != nil {
 				return err
 			}
 		}
+		for _, getter := range getters(n) {
+			g.mutability[getter.Name] = getter.Mutability
+			if err := g.Generate(getter); err != nil {
+				return err
+			}
+		}
+		for _, name := range g.embeds {
+			if err := g.embedContract(name); err != nil {
+				return err
+			}
+		}
 		g.emit(OpEnd, "CONTRACT") // Más específico para el RYC
+		output.InitCode = g.initCode[initStart:]
+		output.Instructions = g.instructions[runtimeStart:]
+		output.ABI = g.abi[abiStart:]
+		output.Storage = storageLayout(n)
+		g.outputs = append(g.outputs, output)
 	case *ast.EnumStatement:
 		g.emit(OpEnum, n.Name)
 		for _, value := range n.Values {
@@ -301,19 +443,63 @@ func (g *Generator) Generate(node ast.Node) error {
 		if err := g.Generate(n.Value); err != nil {
 			return err
 		}
-		g.emit(OpErr)
+		// Los errores personalizados ya emiten su propio OpRevert.
+		if _, ok := n.Value.(*ast.ErrorCallExpression); !ok {
+			g.emit(OpErr)
+		}
+	case *ast.ModifierStatement:
+		// Los modificadores no generan código propio: se insertan en cada función que los aplica.
+	case *ast.PlaceholderStatement:
+		return fmt.Errorf("codegen: '_;' solo puede usarse dentro de un modificador")
+	case *ast.ErrorStatement:
+		errorABI := ABIFunction{
+			Name:     n.Name,
+			Type:     "error",
+			Inputs:   []ABIType{},
+			Selector: NewSelector(n.Name, keyTypes(n.Params)).String(),
+		}
+		for _, param := range n.Params {
+			errorABI.Inputs = append(errorABI.Inputs, ABIType{Name: param.Name, Type: param.Type})
+		}
+		g.abi = append(g.abi, errorABI)
+	case *ast.RevertStatement:
+		if err := g.Generate(n.Error); err != nil {
+			return err
+		}
+	case *ast.ErrorCallExpression:
+		decl, ok := g.errors[n.Name]
+		if !ok {
+			return fmt.Errorf("codegen: error personalizado no declarado '%s'", n.Name)
+		}
+		if len(n.Arguments) != len(decl.Params) {
+			return fmt.Errorf("codegen: el error '%s' espera %d argumentos, recibió %d", n.Name, len(decl.Params), len(n.Arguments))
+		}
+		for i, arg := range n.Arguments {
+			if argType := g.typeOf(arg); argType != "" && argType != decl.Params[i].Type {
+				return fmt.Errorf("codegen: el argumento %d del error '%s' debe ser %s, recibió %s", i+1, n.Name, decl.Params[i].Type, argType)
+			}
+			if err := g.Generate(arg); err != nil {
+				return err
+			}
+		}
+		// Los datos del revert son el selector seguido de los argumentos codificados.
+		g.emit(OpRevert, NewSelector(decl.Name, keyTypes(decl.Params)), uint64(len(n.Arguments)))
 	case *ast.StorageAccessStatement:
 		g.emit(OpLoad, n.Name)
 		for _, param := range n.Params {
-			// El segundo argumento de Param es de tipo *ast.Identifier, no es el valor directamente.
-			// Necesitas acceder a `param.Value` si quieres el nombre de la variable.
-			// `param` es un *ast.Expression, así que g.Generate(param) sería lo correcto si evalúa a un valor.
-			g.emit(OpLoad, param.Value)
-
+			// Las claves pueden ser cualquier expresión (parámetros, llamadas predefinidas, etc).
+			if err := g.Generate(param); err != nil {
+				return err
+			}
 		}
 		g.emit(OpEnd, "LOAD") // Podría ser innecesario dependiendo del significado de END_LOAD.
 	case *ast.StorageStatement:
 		g.emit(OpStore, n.Name)
+		for _, param := range n.Params {
+			if err := g.Generate(param); err != nil {
+				return err
+			}
+		}
 
 		if n.Value != nil {
 			if err := g.Generate(n.Value); err != nil {
@@ -322,36 +508,62 @@ func (g *Generator) Generate(node ast.Node) error {
 		}
 		g.emit(OpEnd, "STORE") // Marca el final de la operación de almacenamiento.
 	case *ast.FuncStatement:
-		funcABI := ABIFunction{
-			Name:       n.Name,
-			Type:       "function",
-			Visibility: "public",
-		}
-		if !n.Public {
-			funcABI.Visibility = "private"
-		}
-		for _, param := range n.Params {
-			funcABI.Inputs = append(funcABI.Inputs, ABIType{Type: param.Type})
+		if n.Body == nil {
+			return fmt.Errorf("codegen: la función '%s' no tiene cuerpo", n.Name)
 		}
-		if n.ReturnType.Type != "" {
-			funcABI.Outputs = append(funcABI.Outputs, ABIType{Type: n.ReturnType.Type})
+		if n.Mutability == MutabilityPayable && !n.Public {
+			return fmt.Errorf("codegen: la función priv '%s' no puede ser payable", n.Name)
 		}
+		funcABI := functionABI(n)
+		funcABI.StateMut = g.mutability[n.Name]
 
 		g.abi = append(g.abi, funcABI)
 		g.currentFunc = &funcABI // Establece la función actual para referencia.
+		g.scope = paramScope(n.Params)
+
+		// Las funciones pub que no son payable rechazan el valor solo al llamarse
+		// desde fuera: las llamadas internas (LOAD) entran directamente en FUNC.
+		if n.Public && n.Mutability != MutabilityPayable {
+			g.emit(OpEntry, n.Name)
+			g.generateValueGuard(n.Name)
+			g.emit(OpEnd, "ENTRY")
+		}
 
 		// Emite la instrucción de función con el nombre y el tipo de retorno.
 		g.emit(OpFunc, n.Name, n.ReturnType.Type)
 
+		if err := g.generateModified(n.Body, n.Modifiers); err != nil {
+			return err
+		}
+
+		g.emit(OpEnd, "FUNC")
+		g.currentFunc = nil // Limpia la función actual.
+	case *ast.ConstructorStatement:
+		constructorABI := ABIFunction{
+			Type:     "constructor",
+			Inputs:   []ABIType{},
+			StateMut: MutabilityNonPayable,
+		}
+		for _, param := range n.Params {
+			constructorABI.Inputs = append(constructorABI.Inputs, ABIType{Name: param.Name, Type: param.Type})
+		}
+		g.abi = append(g.abi, constructorABI)
+		g.scope = paramScope(n.Params)
+
+		g.emit(OpConstructor)
 		for _, stmt := range n.Body {
 			if err := g.Generate(stmt); err != nil {
 				return err
 			}
 		}
-
-		g.emit(OpEnd, "FUNC")
-		g.currentFunc = nil // Limpia la función actual.
+		g.emit(OpEnd, "CONSTRUCTOR")
 	case *ast.VariableStatement:
+		switch n.Kind {
+		case ast.KindConst:
+			return nil // Se inserta donde se usa.
+		case ast.KindImmutable:
+			return g.generateSetImmutable(n.Name, n.Value)
+		}
 		g.emit(OpStore, n.Name)
 		if n.Value != nil {
 			if err := g.Generate(n.Value); err != nil {
@@ -360,6 +572,9 @@ func (g *Generator) Generate(node ast.Node) error {
 		}
 		g.emit(OpEnd, "STORE") // Marca el final de la operación de almacenamiento.
 	case *ast.VariableStatementNonInitializer:
+		if n.Kind == ast.KindImmutable {
+			return nil // Se asigna en el constructor.
+		}
 		g.emit(OpStore, n.Name)
 
 		switch n.Token.Type {
@@ -380,7 +595,12 @@ func (g *Generator) Generate(node ast.Node) error {
 
 		g.emit(OpEnd, "STORE") // Marca el final de la operación de almacenamiento.
 	case *ast.ConstExpression:
-		g.emit(OpConst, n.Name)
+		if valueType := g.typeOf(n.Value); valueType != "" && valueType != n.Token.Literal {
+			return fmt.Errorf("codegen: no se puede asignar %s a '%s' de tipo %s", valueType, n.Name, n.Token.Literal)
+		}
+		name := g.declareLocal(n.Name)
+		g.scope[name] = n.Token.Literal
+		g.emit(OpConst, name)
 		if n.Value != nil {
 			if err := g.Generate(n.Value); err != nil {
 				return err
@@ -388,6 +608,12 @@ func (g *Generator) Generate(node ast.Node) error {
 		}
 		g.emit(OpEnd, "CONST") // Marca el final de la operación de constante.
 	case *ast.ReturnStatement:
+		if g.currentFunc != nil && len(g.currentFunc.Outputs) > 0 && n.Value != nil {
+			expected := g.currentFunc.Outputs[0].Type
+			if valueType := g.typeOf(n.Value); valueType != "" && expected != "void" && valueType != expected {
+				return fmt.Errorf("codegen: la función '%s' devuelve %s, no %s", g.currentFunc.Name, expected, valueType)
+			}
+		}
 		if n.Value != nil {
 			if err := g.Generate(n.Value); err != nil {
 				return err
@@ -396,6 +622,12 @@ func (g *Generator) Generate(node ast.Node) error {
 		g.emit(OpReturn) // Unificada la emisión de OpReturn.
 
 	case *ast.BinaryExpression:
+		if n.Operator == "==" || n.Operator == "!=" {
+			left, right := g.typeOf(n.Left), g.typeOf(n.Right)
+			if left != "" && right != "" && left != right {
+				return fmt.Errorf("codegen: no se puede comparar %s con %s en '%s'", left, right, n.String())
+			}
+		}
 		if err := g.Generate(n.Left); err != nil {
 			return err
 		}
@@ -419,6 +651,12 @@ func (g *Generator) Generate(node ast.Node) error {
 			g.emit(OpLt)
 		case ">":
 			g.emit(OpGt)
+		case "<=": // a <= b equivale a !(a > b)
+			g.emit(OpGt)
+			g.emit(OpNot)
+		case ">=": // a >= b equivale a !(a < b)
+			g.emit(OpLt)
+			g.emit(OpNot)
 		case "&&":
 			g.emit(OpAnd)
 		case "||":
@@ -449,6 +687,9 @@ func (g *Generator) Generate(node ast.Node) error {
 		}
 
 	case *ast.CallExpression:
+		if b, ok := g.lookupBuiltin(n.Function.String()); ok {
+			return g.generateBuiltin(n.Function.String(), b, n.Arguments)
+		}
 		// Evalúa los argumentos antes de la función.
 		for _, arg := range n.Arguments {
 			if err := g.Generate(arg); err != nil {
@@ -462,8 +703,32 @@ func (g *Generator) Generate(node ast.Node) error {
 		// Pasa la representación de la función y el número de argumentos para el 'Raw'
 		g.emit(OpCall, n.Function.String(), len(n.Arguments))
 
+	case *ast.MemberExpression:
+		return g.generateMember(n)
+
+	case *ast.CreateExpression:
+		return g.generateCreate(n)
+	case *ast.ExternalCallExpression:
+		return g.generateExternalCall(n)
+
 	case *ast.Identifier:
-		g.emit(OpLoad, n.Value)
+		name := g.localName(n.Value)
+		if _, local := g.scope[name]; !local {
+			if value, ok := g.constants[n.Value]; ok {
+				return g.Generate(value)
+			}
+			if g.immutables[n.Value] {
+				// Durante el despliegue solo se pueden leer los inmutables ya asignados.
+				if g.assigned != nil && !g.assigned[n.Value] {
+					return fmt.Errorf("codegen: el inmutable '%s' se lee antes de asignarse", n.Value)
+				}
+				g.emit(OpImmutable, n.Value)
+				return nil
+			}
+		}
+		g.emit(OpLoad, name)
+	case *ast.AssignStatement:
+		return g.generateAssign(n)
 
 	default:
 		return fmt.Errorf("codegen: tipo de nodo AST desconocido para la generación: %T", n)
@@ -472,11 +737,322 @@ func (g *Generator) Generate(node ast.Node) error {
 	return nil // Retorna nil si todo va bien.
 }
 
+// functionABI construye la entrada ABI de una función, incluido su selector.
+func functionABI(n *ast.FuncStatement) ABIFunction {
+	funcABI := ABIFunction{
+		Name:       n.Name,
+		Type:       "function",
+		Visibility: "public",
+		Selector:   NewSelector(n.Name, keyTypes(n.Params)).String(),
+	}
+	if !n.Public {
+		funcABI.Visibility = "private"
+	}
+	for _, param := range n.Params {
+		funcABI.Inputs = append(funcABI.Inputs, ABIType{Type: param.Type})
+	}
+	if n.ReturnType.Type != "" {
+		funcABI.Outputs = append(funcABI.Outputs, ABIType{Type: n.ReturnType.Type})
+	}
+	return funcABI
+}
+
+// generateInterface registra la ABI de una interfaz. Las interfaces solo describen
+// funciones de otros contratos, por lo que no emiten instrucciones.
+func (g *Generator) generateInterface(class *ast.ClassStatement) error {
+	abi := make(ABI, 0)
+	for _, stmt := range class.Body {
+		fn, ok := stmt.(*ast.FuncStatement)
+		if !ok {
+			return fmt.Errorf("codegen: la interfaz '%s' solo puede declarar funciones", class.Name)
+		}
+		if fn.Body != nil {
+			return fmt.Errorf("codegen: la función '%s' de la interfaz '%s' no puede tener cuerpo", fn.Name, class.Name)
+		}
+		entry := functionABI(fn)
+		entry.Visibility = "external"
+		entry.StateMut = fn.Mutability
+		if entry.StateMut == "" {
+			entry.StateMut = MutabilityNonPayable
+		}
+		abi = append(abi, entry)
+	}
+	g.interfaceABI[class.Name] = abi
+	g.outputs = append(g.outputs, &Contract{Name: class.Name, IsInterface: true, ABI: abi})
+	return nil
+}
+
+// generateExternalCall genera una llamada a otro contrato a través de una interfaz:
+// Interfaz(dirección).función(args). La VM codifica los argumentos tras el selector
+// y decodifica el valor devuelto según el tipo indicado.
+func (g *Generator) generateExternalCall(n *ast.ExternalCallExpression) error {
+	fn, err := g.interfaceFunction(n)
+	if err != nil {
+		return err
+	}
+
+	if addrType := g.typeOf(n.Address); addrType != "" && addrType != "address" {
+		return fmt.Errorf("codegen: '%s(...)' espera una dirección, recibió %s", n.Interface, addrType)
+	}
+	if len(n.Arguments) != len(fn.Params) {
+		return fmt.Errorf("codegen: '%s.%s' espera %d argumentos, recibió %d", n.Interface, n.Method, len(fn.Params), len(n.Arguments))
+	}
+	for i, arg := range n.Arguments {
+		if argType := g.typeOf(arg); argType != "" && argType != fn.Params[i].Type {
+			return fmt.Errorf("codegen: el argumento %d de '%s.%s' debe ser %s, recibió %s", i+1, n.Interface, n.Method, fn.Params[i].Type, argType)
+		}
+	}
+
+	if err := g.Generate(n.Address); err != nil {
+		return err
+	}
+	for _, arg := range n.Arguments {
+		if err := g.Generate(arg); err != nil {
+			return err
+		}
+	}
+	g.emit(OpExtCall, NewSelector(fn.Name, keyTypes(fn.Params)), uint64(len(n.Arguments)), fn.ReturnType.Type)
+	return nil
+}
+
+// interfaceFunction busca la función llamada en la interfaz indicada.
+func (g *Generator) interfaceFunction(n *ast.ExternalCallExpression) (*ast.FuncStatement, error) {
+	iface, ok := g.interfaces[n.Interface]
+	if !ok {
+		return nil, fmt.Errorf("codegen: interfaz no declarada '%s'", n.Interface)
+	}
+	for _, stmt := range iface.Body {
+		if fn, ok := stmt.(*ast.FuncStatement); ok && fn.Name == n.Method {
+			return fn, nil
+		}
+	}
+	return nil, fmt.Errorf("codegen: la interfaz '%s' no declara la función '%s'", n.Interface, n.Method)
+}
+
+// generateInit genera el código de inicialización del contrato: primero la
+// inicialización de las variables, después el constructor y por último DEPLOY,
+// que instala el código de runtime.
+func (g *Generator) generateInit(class *ast.ClassStatement) error {
+	runtime := g.instructions
+	g.instructions = g.initCode
+	defer func() {
+		g.initCode = g.instructions
+		g.instructions = runtime
+	}()
+
+	var constructor *ast.ConstructorStatement
+	g.assigned = make(map[string]bool)
+	defer func() { g.assigned = nil }()
+
+	g.emit(OpContract, class.Name)
+	g.embeds = nil
+	for _, stmt := range class.Body {
+		switch s := stmt.(type) {
+		case *ast.VariableStatement, *ast.VariableStatementNonInitializer:
+			if err := g.Generate(s); err != nil {
+				return err
+			}
+		case *ast.ConstructorStatement:
+			if constructor != nil {
+				return fmt.Errorf("codegen: el contrato '%s' declara más de un constructor", class.Name)
+			}
+			constructor = s
+		}
+	}
+	if constructor != nil {
+		if err := g.Generate(constructor); err != nil {
+			return err
+		}
+	}
+	for _, stmt := range class.Body {
+		if decl, ok := stmt.(*ast.VariableStatementNonInitializer); ok && decl.Kind == ast.KindImmutable && !g.assigned[decl.Name] {
+			return g.withPosition(fmt.Errorf("codegen: el inmutable '%s' no se asigna en el constructor", decl.Name), decl)
+		}
+	}
+	g.emit(OpDeploy)
+	for _, name := range g.embeds {
+		if err := g.embedContract(name); err != nil {
+			return err
+		}
+	}
+	g.emit(OpEnd, "CONTRACT")
+
+	return nil
+}
+
+// collectDeclarations registra los errores personalizados y los modificadores del
+// contrato antes de generar su cuerpo, de modo que puedan usarse antes de su declaración.
+func (g *Generator) collectDeclarations(class *ast.ClassStatement) error {
+	g.errors = make(map[string]*ast.ErrorStatement)
+	g.modifiers = make(map[string]*ast.ModifierStatement)
+	g.storages = make(map[string]*ast.StorageDeclaration)
+	g.variables = make(map[string]string)
+	g.constants = make(map[string]ast.Expression)
+	g.immutables = make(map[string]bool)
+	for _, stmt := range class.Body {
+		switch decl := stmt.(type) {
+		case *ast.StorageDeclaration:
+			g.storages[decl.Name] = decl
+		case *ast.VariableStatement:
+			g.variables[decl.Name] = decl.Token.Literal
+			switch decl.Kind {
+			case ast.KindConst:
+				g.constants[decl.Name] = decl.Value
+			case ast.KindImmutable:
+				g.immutables[decl.Name] = true
+			}
+		case *ast.VariableStatementNonInitializer:
+			g.variables[decl.Name] = decl.Token.Literal
+			switch decl.Kind {
+			case ast.KindConst:
+				return g.withPosition(fmt.Errorf("codegen: la constante '%s' necesita un valor", decl.Name), decl)
+			case ast.KindImmutable:
+				g.immutables[decl.Name] = true
+			}
+		case *ast.ErrorStatement:
+			if _, exists := g.errors[decl.Name]; exists {
+				return fmt.Errorf("codegen: error personalizado '%s' declarado más de una vez", decl.Name)
+			}
+			g.errors[decl.Name] = decl
+		case *ast.ModifierStatement:
+			if _, exists := g.modifiers[decl.Name]; exists {
+				return fmt.Errorf("codegen: modificador '%s' declarado más de una vez", decl.Name)
+			}
+			g.modifiers[decl.Name] = decl
+		}
+	}
+	return nil
+}
+
+// generateModified genera el cuerpo de una función envuelto por sus modificadores.
+// Cada modificador se inserta en línea y su `_;` se sustituye por el siguiente
+// modificador o, en el último, por el cuerpo de la función.
+func (g *Generator) generateModified(body []ast.Statement, modifiers []ast.ModifierInvocation) error {
+	if len(modifiers) == 0 {
+		for _, stmt := range body {
+			if err := g.Generate(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	invocation := modifiers[0]
+	decl, ok := g.modifiers[invocation.Name]
+	if !ok {
+		return fmt.Errorf("codegen: modificador no declarado '%s'", invocation.Name)
+	}
+	if len(invocation.Arguments) != len(decl.Params) {
+		return fmt.Errorf("codegen: el modificador '%s' espera %d argumentos, recibió %d", decl.Name, len(decl.Params), len(invocation.Arguments))
+	}
+
+	// Los parámetros y las constantes locales del modificador se emiten con un
+	// nombre propio para no ocultar los de la función ni los de otros
+	// modificadores. Los argumentos se evalúan en el ámbito de la función.
+	outer := g.modifier
+	local := &modifierScope{prefix: fmt.Sprintf("__mod_%s_%d_", decl.Name, len(modifiers)), names: make(map[string]string)}
+	defer func() { g.modifier = outer }()
+	for i, param := range decl.Params {
+		name := local.prefix + param.Name
+		g.emit(OpConst, name)
+		if err := g.Generate(invocation.Arguments[i]); err != nil {
+			return err
+		}
+		g.emit(OpEnd, "CONST")
+		local.names[param.Name] = name
+		g.scope[name] = param.Type
+	}
+
+	placeholders := 0
+	for _, stmt := range decl.Body {
+		g.modifier = local
+		if _, ok := stmt.(*ast.PlaceholderStatement); ok {
+			placeholders++
+			// El cuerpo de la función no ve las constantes del modificador, y el
+			// resto del modificador no ve las de la función.
+			saved := maps.Clone(g.scope)
+			g.modifier = outer
+			if err := g.generateModified(body, modifiers[1:]); err != nil {
+				return err
+			}
+			g.scope = saved
+			continue
+		}
+		if err := g.Generate(stmt); err != nil {
+			return err
+		}
+	}
+	if placeholders == 0 {
+		return fmt.Errorf("codegen: el modificador '%s' no contiene '_;'", decl.Name)
+	}
+
+	return nil
+}
+
+// modifierScope guarda los nombres con que se emiten los parámetros y las
+// constantes locales de un modificador insertado en una función.
+type modifierScope struct {
+	prefix string            // Prefijo propio del modificador, p. ej. __mod_onlyOwner_1_.
+	names  map[string]string // Nombre en el código fuente -> nombre emitido.
+}
+
+// localName devuelve el nombre con que se emite el parámetro o la constante local name.
+func (g *Generator) localName(name string) string {
+	if g.modifier != nil {
+		if emitted, ok := g.modifier.names[name]; ok {
+			return emitted
+		}
+	}
+	return name
+}
+
+// declareLocal devuelve el nombre con que se emite una nueva constante local;
+// dentro de un modificador lleva su prefijo.
+func (g *Generator) declareLocal(name string) string {
+	if g.modifier == nil {
+		return name
+	}
+	emitted := g.modifier.prefix + name
+	g.modifier.names[name] = emitted
+	return emitted
+}
+
+// paramScope crea el ámbito local de una función a partir de sus parámetros.
+func paramScope(params []ast.Key) map[string]string {
+	scope := make(map[string]string, len(params))
+	for _, param := range params {
+		scope[param.Name] = param.Type
+	}
+	return scope
+}
+
+// keyTypes devuelve los tipos de una lista de parámetros.
+func keyTypes(params []ast.Key) []string {
+	types := make([]string, 0, len(params))
+	for _, param := range params {
+		types = append(types, param.Type)
+	}
+	return types
+}
+
 // WriteABI escribe la ABI generada en un archivo JSON.
 func (g *Generator) WriteABI(filename string) error {
-	abiData, err := json.MarshalIndent(g.abi, "", "  ")
+	return writeABI(filename, g.abi)
+}
+
+// WriteInterfaceABI escribe la ABI de la interfaz indicada en un archivo JSON.
+func (g *Generator) WriteInterfaceABI(name string, filename string) error {
+	abi, ok := g.interfaceABI[name]
+	if !ok {
+		return fmt.Errorf("interfaz desconocida '%s'", name)
+	}
+	return writeABI(filename, abi)
+}
+
+func writeABI(filename string, abi ABI) error {
+	abiData, err := encodeABI(abi)
 	if err != nil {
-		return fmt.Errorf("error al serializar ABI: %w", err)
+		return err
 	}
 	if err := os.WriteFile(filename, abiData, 0644); err != nil {
 		return fmt.Errorf("error al escribir archivo ABI '%s': %w", filename, err)
@@ -486,24 +1062,68 @@ func (g *Generator) WriteABI(filename string) error {
 
 // WriteRYC escribe el código de Ryot (bytecode legible por humanos) en un archivo.
 func (g *Generator) WriteRYC(filename string, codeHash string) error {
+	return writeRYC(filename, g.contractName, "Bytecode disassembly", g.instructions, codeHash)
+}
+
+// WriteInitRYC escribe el desensamblado del código de inicialización en un archivo.
+func (g *Generator) WriteInitRYC(filename string, codeHash string) error {
+	return writeRYC(filename, g.contractName, "Init bytecode disassembly", g.initCode, codeHash)
+}
+
+func writeRYC(filename string, name string, title string, instructions []Instruction, codeHash string) error {
+	if err := os.WriteFile(filename, encodeRYC(name, title, instructions, codeHash), 0644); err != nil {
+		return fmt.Errorf("error al escribir archivo RYC '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// encodeABI serializa la ABI como JSON indentado.
+func encodeABI(abi ABI) ([]byte, error) {
+	abiData, err := json.MarshalIndent(abi, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar ABI: %w", err)
+	}
+	return abiData, nil
+}
+
+// encodeRYC genera el desensamblado legible de una lista de instrucciones.
+func encodeRYC(name string, title string, instructions []Instruction, codeHash string) []byte {
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("; ABI: %s\n", g.contractName))
-	builder.WriteString("; Bytecode disassembly\n")
+	builder.WriteString(fmt.Sprintf("; ABI: %s\n", name))
+	builder.WriteString("; " + title + "\n")
 	builder.WriteString("; Source code hash: " + "0x" + codeHash + "\n\n")
 
-	for _, instr := range g.instructions {
+	for _, instr := range instructions {
 		// La propiedad 'Raw' ahora es generada consistentemente por 'emit'.
 		builder.WriteString(instr.Raw + "\n")
 	}
 
-	if err := os.WriteFile(filename, []byte(builder.String()), 0644); err != nil {
-		return fmt.Errorf("error al escribir archivo RYC '%s': %w", filename, err)
-	}
-	return nil
+	return []byte(builder.String())
 }
 
 // WriteRYBC escribe el bytecode binario de Ryot en un archivo.
 func (g *Generator) WriteRYBC(filename string, codehash []byte) error {
+	return writeRYBC(filename, g.instructions, codehash)
+}
+
+// WriteInitRYBC escribe el bytecode binario del código de inicialización en un archivo.
+func (g *Generator) WriteInitRYBC(filename string, codehash []byte) error {
+	return writeRYBC(filename, g.initCode, codehash)
+}
+
+func writeRYBC(filename string, instructions []Instruction, codehash []byte) error {
+	bytecode, err := encodeRYBC(instructions, codehash)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, bytecode, 0644); err != nil {
+		return fmt.Errorf("error al escribir archivo RYBC '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// encodeRYBC serializa una lista de instrucciones en el formato binario RYBC.
+func encodeRYBC(instructions []Instruction, codehash []byte) ([]byte, error) {
 	var bytecode []byte
 
 	// Número mágico para Ryot bytecode (0xRYBC)
@@ -514,11 +1134,11 @@ func (g *Generator) WriteRYBC(filename string, codehash []byte) error {
 
 	// Añadir el hash del código al bytecode
 	if len(codehash) != 32 {
-		return fmt.Errorf("codehash debe tener 32 bytes, tiene %d", len(codehash))
+		return nil, fmt.Errorf("codehash debe tener 32 bytes, tiene %d", len(codehash))
 	}
 	bytecode = append(bytecode, codehash...)
 
-	for _, instr := range g.instructions {
+	for _, instr := range instructions {
 		bytecode = append(bytecode, byte(instr.Opcode))
 		// Serializar argumentos
 		for _, arg := range instr.Args {
@@ -550,16 +1170,15 @@ func (g *Generator) WriteRYBC(filename string, codehash []byte) error {
 				} else {
 					bytecode = append(bytecode, 0x00)
 				}
+			case Selector:
+				bytecode = append(bytecode, v[:]...)
 			// Añadir casos para otros tipos si son posibles argumentos (e.g., float, []byte)
 			default:
 				// Manejar tipos de argumentos no serializables si es necesario
-				return fmt.Errorf("tipo de argumento no serializable en bytecode: %T", v)
+				return nil, fmt.Errorf("tipo de argumento no serializable en bytecode: %T", v)
 			}
 		}
 	}
 
-	if err := os.WriteFile(filename, bytecode, 0644); err != nil {
-		return fmt.Errorf("error al escribir archivo RYBC '%s': %w", filename, err)
-	}
-	return nil
+	return bytecode, nil
 }
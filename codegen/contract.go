@@ -0,0 +1,96 @@
+package codegen
+
+import "github.com/polarysfoundation/ryot/ast"
+
+// Contract contiene el código generado para una sola clase del programa. Las
+// interfaces solo tienen ABI.
+type Contract struct {
+	Name         string         // Nombre de la clase.
+	IsInterface  bool           // Las interfaces no generan bytecode.
+	InitCode     []Instruction  // Código de inicialización: variables y constructor.
+	Instructions []Instruction  // Código de runtime, incluidos los contratos incrustados para create.
+	ABI          ABI            // ABI propia de la clase.
+	Storage      []StorageEntry // Variables y mapas de almacenamiento, en orden de declaración.
+}
+
+// StorageEntry describe una entrada del almacenamiento de un contrato. Las
+// variables de estado no tienen claves.
+type StorageEntry struct {
+	Name string   `json:"name"`
+	Keys []string `json:"keys,omitempty"` // Tipos de las claves de un mapa.
+	Type string   `json:"type"`           // Tipo del valor almacenado.
+}
+
+// storageLayout devuelve las entradas de almacenamiento declaradas en una clase.
+func storageLayout(class *ast.ClassStatement) []StorageEntry {
+	var layout []StorageEntry
+	for _, stmt := range class.Body {
+		switch decl := stmt.(type) {
+		case *ast.StorageDeclaration:
+			entry := StorageEntry{Name: decl.Name, Type: decl.Value.Type}
+			for _, key := range decl.Params {
+				entry.Keys = append(entry.Keys, key.Type)
+			}
+			layout = append(layout, entry)
+		case *ast.VariableStatement:
+			if decl.Kind == "" { // Las constantes y los inmutables no ocupan storage.
+				layout = append(layout, StorageEntry{Name: decl.Name, Type: decl.Token.Literal})
+			}
+		case *ast.VariableStatementNonInitializer:
+			if decl.Kind == "" {
+				layout = append(layout, StorageEntry{Name: decl.Name, Type: decl.Token.Literal})
+			}
+		}
+	}
+	return layout
+}
+
+// WriteABI escribe la ABI del contrato en un archivo JSON.
+func (c *Contract) WriteABI(filename string) error {
+	return writeABI(filename, c.ABI)
+}
+
+// WriteRYC escribe el desensamblado del código de runtime del contrato.
+func (c *Contract) WriteRYC(filename string, codeHash string) error {
+	return writeRYC(filename, c.Name, "Bytecode disassembly", c.Instructions, codeHash)
+}
+
+// WriteInitRYC escribe el desensamblado del código de inicialización del contrato.
+func (c *Contract) WriteInitRYC(filename string, codeHash string) error {
+	return writeRYC(filename, c.Name, "Init bytecode disassembly", c.InitCode, codeHash)
+}
+
+// WriteRYBC escribe el bytecode binario de runtime del contrato.
+func (c *Contract) WriteRYBC(filename string, codehash []byte) error {
+	return writeRYBC(filename, c.Instructions, codehash)
+}
+
+// WriteInitRYBC escribe el bytecode binario del código de inicialización del contrato.
+func (c *Contract) WriteInitRYBC(filename string, codehash []byte) error {
+	return writeRYBC(filename, c.InitCode, codehash)
+}
+
+// EncodeABI devuelve la ABI del contrato serializada como JSON.
+func (c *Contract) EncodeABI() ([]byte, error) {
+	return encodeABI(c.ABI)
+}
+
+// EncodeRYC devuelve el desensamblado del código de runtime del contrato.
+func (c *Contract) EncodeRYC(codeHash string) []byte {
+	return encodeRYC(c.Name, "Bytecode disassembly", c.Instructions, codeHash)
+}
+
+// EncodeInitRYC devuelve el desensamblado del código de inicialización del contrato.
+func (c *Contract) EncodeInitRYC(codeHash string) []byte {
+	return encodeRYC(c.Name, "Init bytecode disassembly", c.InitCode, codeHash)
+}
+
+// EncodeRYBC devuelve el bytecode binario de runtime del contrato.
+func (c *Contract) EncodeRYBC(codehash []byte) ([]byte, error) {
+	return encodeRYBC(c.Instructions, codehash)
+}
+
+// EncodeInitRYBC devuelve el bytecode binario del código de inicialización del contrato.
+func (c *Contract) EncodeInitRYBC(codehash []byte) ([]byte, error) {
+	return encodeRYBC(c.InitCode, codehash)
+}
@@ -0,0 +1,70 @@
+package codegen
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/polarysfoundation/ryot/ast"
+)
+
+// generateCreate genera create Hijo(args): evalúa los argumentos del constructor
+// y emite CREATE, que despliega el contrato incrustado y deja su dirección en la pila.
+func (g *Generator) generateCreate(n *ast.CreateExpression) error {
+	child, ok := g.contracts[n.Contract]
+	if !ok {
+		if _, isInterface := g.interfaces[n.Contract]; isInterface {
+			return fmt.Errorf("codegen: no se puede crear la interfaz '%s'", n.Contract)
+		}
+		return fmt.Errorf("codegen: contrato no declarado '%s'", n.Contract)
+	}
+	if n.Contract == g.contractName || slices.Contains(g.creating, n.Contract) {
+		return fmt.Errorf("codegen: creación circular de '%s' desde '%s'", n.Contract, g.contractName)
+	}
+
+	var params []ast.Key
+	for _, stmt := range child.Body {
+		if c, ok := stmt.(*ast.ConstructorStatement); ok {
+			params = c.Params
+		}
+	}
+	if len(n.Arguments) != len(params) {
+		return fmt.Errorf("codegen: el constructor de '%s' espera %d argumentos, recibió %d", n.Contract, len(params), len(n.Arguments))
+	}
+	for i, arg := range n.Arguments {
+		if argType := g.typeOf(arg); argType != "" && argType != params[i].Type {
+			return fmt.Errorf("codegen: el argumento %d del constructor de '%s' debe ser %s, recibió %s", i+1, n.Contract, params[i].Type, argType)
+		}
+		if err := g.Generate(arg); err != nil {
+			return err
+		}
+	}
+
+	g.emit(OpCreate, n.Contract, uint64(len(n.Arguments)))
+	if !slices.Contains(g.embeds, n.Contract) {
+		g.embeds = append(g.embeds, n.Contract)
+	}
+	return nil
+}
+
+// embedContract emite la sección EMBED con el código de inicialización y de runtime
+// del contrato indicado, que CREATE usa para desplegarlo. El contrato se genera con
+// un generador aparte para no mezclar sus declaraciones con las del contrato actual.
+func (g *Generator) embedContract(name string) error {
+	sub := New()
+	sub.classes = g.classes
+	sub.interfaces = g.interfaces
+	sub.contracts = g.contracts
+	sub.creating = append(slices.Clone(g.creating), g.contractName)
+	sub.labelCounter = g.labelCounter // Las etiquetas no deben repetirse dentro del mismo código.
+
+	if err := sub.Generate(g.contracts[name]); err != nil {
+		return err
+	}
+	g.labelCounter = sub.labelCounter
+
+	g.emit(OpEmbed, name)
+	g.instructions = append(g.instructions, sub.initCode...)
+	g.instructions = append(g.instructions, sub.instructions...)
+	g.emit(OpEnd, "EMBED")
+	return nil
+}
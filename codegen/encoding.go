@@ -0,0 +1,122 @@
+package codegen
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	pm256 "github.com/polarysfoundation/pm-256"
+	"github.com/polarysfoundation/ryot/ast"
+)
+
+// Codificación canónica de valores de Ryot. Es la misma que aplica la VM al
+// ejecutar HASH, de modo que pm256(...) produce el mismo resultado tanto si se
+// pliega en tiempo de compilación como si se calcula en ejecución:
+//
+//	uint64  8 bytes big-endian
+//	bool    1 byte (0x00 o 0x01)
+//	byte    1 byte
+//	address 15 bytes (los 30 dígitos hexadecimales tras el prefijo 1cx)
+//	hash    32 bytes
+//	string  longitud en 8 bytes big-endian seguida de los bytes UTF-8
+//
+// Los argumentos se concatenan sin separadores.
+
+// EncodeUint64 codifica un uint64.
+func EncodeUint64(v uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v)
+	return buf
+}
+
+// EncodeBool codifica un bool.
+func EncodeBool(v bool) []byte {
+	if v {
+		return []byte{0x01}
+	}
+	return []byte{0x00}
+}
+
+// EncodeString codifica un string con su longitud como prefijo.
+func EncodeString(v string) []byte {
+	return append(EncodeUint64(uint64(len(v))), []byte(v)...)
+}
+
+// EncodeAddress codifica una dirección 1cx... en sus 15 bytes.
+func EncodeAddress(v string) ([]byte, error) {
+	if !strings.HasPrefix(v, "1cx") || len(v) != 33 {
+		return nil, fmt.Errorf("dirección inválida '%s'", v)
+	}
+	return hex.DecodeString(v[3:])
+}
+
+// EncodeHash codifica un hash 0x... en sus 32 bytes.
+func EncodeHash(v string) ([]byte, error) {
+	if !strings.HasPrefix(v, "0x") || len(v) != 66 {
+		return nil, fmt.Errorf("hash inválido '%s'", v)
+	}
+	return hex.DecodeString(v[2:])
+}
+
+// HashEncoded calcula el pm-256 de argumentos ya codificados y lo devuelve como literal 0x....
+func HashEncoded(args ...[]byte) string {
+	var data []byte
+	for _, arg := range args {
+		data = append(data, arg...)
+	}
+	sum := pm256.Sum256(data)
+	return "0x" + hex.EncodeToString(sum[:])
+}
+
+// encodable indica si un tipo puede pasarse a pm256(...).
+func encodable(t string) bool {
+	switch t {
+	case "uint64", "bool", "byte", "address", "hash", "string":
+		return true
+	}
+	return false
+}
+
+// encodeConstant devuelve la codificación canónica de una expresión constante.
+// El segundo valor es false si la expresión no puede evaluarse en compilación.
+func encodeConstant(expr ast.Expression) ([]byte, bool) {
+	switch n := expr.(type) {
+	case *ast.IntegerLiteral:
+		return EncodeUint64(n.Value), true
+	case *ast.BooleanLiteral:
+		return EncodeBool(n.Value), true
+	case *ast.StringLiteral:
+		return EncodeString(n.Value), true
+	case *ast.AddressExpression:
+		b, err := EncodeAddress(n.Value)
+		return b, err == nil
+	case *ast.HashLiteral:
+		b, err := EncodeHash(n.Value)
+		return b, err == nil
+	case *ast.CallExpression:
+		if n.Function.String() != "pm256" {
+			return nil, false
+		}
+		digest, ok := foldPM256(n.Arguments)
+		if !ok {
+			return nil, false
+		}
+		b, err := EncodeHash(digest)
+		return b, err == nil
+	}
+	return nil, false
+}
+
+// foldPM256 calcula pm256(args) en tiempo de compilación si todos los argumentos son constantes.
+func foldPM256(args []ast.Expression) (string, bool) {
+	encoded := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		b, ok := encodeConstant(arg)
+		if !ok {
+			return "", false
+		}
+		encoded = append(encoded, b)
+	}
+	return HashEncoded(encoded...), true
+}
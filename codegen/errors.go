@@ -0,0 +1,39 @@
+package codegen
+
+import (
+	"errors"
+
+	"github.com/polarysfoundation/ryot/ast"
+)
+
+// Error es un error de generación de código junto con la posición en el código
+// fuente del nodo que lo produjo. Line y Column empiezan en 1.
+type Error struct {
+	Class  string // Clase que se estaba generando.
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error devuelve el mensaje original, sin la posición.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap devuelve el error original.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// withPosition añade a err la posición de node si todavía no tiene una.
+func (g *Generator) withPosition(err error, node ast.Node) error {
+	var positioned *Error
+	if errors.As(err, &positioned) {
+		return err
+	}
+	tok, ok := ast.TokenOf(node)
+	if !ok || tok.Line == 0 {
+		return err
+	}
+	return &Error{Class: g.contractName, Line: tok.Line, Column: tok.Column, Err: err}
+}
@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// getters devuelve las funciones de lectura que se generan para las variables y
+// los storages pub de class. Los getters de los storages reciben las claves como
+// parámetros. Una función del contrato con el mismo nombre tiene prioridad sobre
+// el getter.
+func getters(class *ast.ClassStatement) []*ast.FuncStatement {
+	declared := make(map[string]bool)
+	for _, stmt := range class.Body {
+		if fn, ok := stmt.(*ast.FuncStatement); ok {
+			declared[fn.Name] = true
+		}
+	}
+
+	var fns []*ast.FuncStatement
+	for _, stmt := range class.Body {
+		if getter := memberGetter(stmt); getter != nil && !declared[getter.Name] {
+			fns = append(fns, getter)
+		}
+	}
+	return fns
+}
+
+// memberGetter devuelve el getter que genera stmt, o nil si stmt no es una
+// variable ni un storage pub.
+func memberGetter(stmt ast.Statement) *ast.FuncStatement {
+	switch decl := stmt.(type) {
+	case *ast.VariableStatement:
+		if decl.Public {
+			return variableGetter(decl.Token, decl.Name, decl.Kind)
+		}
+	case *ast.VariableStatementNonInitializer:
+		if decl.Public {
+			return variableGetter(decl.Token, decl.Name, decl.Kind)
+		}
+	case *ast.StorageDeclaration:
+		if decl.Public {
+			return storageGetter(decl)
+		}
+	}
+	return nil
+}
+
+// variableGetter construye el getter de una variable: tok es el token de su tipo
+// y kind su clase. Las constantes no leen el estado, así que su getter es pure.
+func variableGetter(tok token.Token, name, kind string) *ast.FuncStatement {
+	mutability := MutabilityView
+	if kind == ast.KindConst {
+		mutability = MutabilityPure
+	}
+	return &ast.FuncStatement{
+		Token:      tok,
+		Public:     true,
+		Name:       name,
+		Mutability: mutability,
+		ReturnType: ast.Value{Token: tok, Type: tok.Literal},
+		Body:       []ast.Statement{&ast.ReturnStatement{Token: tok, Value: &ast.Identifier{Token: tok, Value: name}}},
+	}
+}
+
+// storageGetter construye el getter de un storage, con una clave por parámetro.
+func storageGetter(decl *ast.StorageDeclaration) *ast.FuncStatement {
+	keys := make([]ast.Expression, 0, len(decl.Params))
+	for _, param := range decl.Params {
+		keys = append(keys, &ast.Identifier{Token: param.Token, Value: param.Name})
+	}
+	return &ast.FuncStatement{
+		Token:      decl.Token,
+		Public:     true,
+		Name:       decl.Name,
+		Params:     decl.Params,
+		Mutability: MutabilityView,
+		ReturnType: decl.Value,
+		Body:       []ast.Statement{&ast.ReturnStatement{Token: decl.Token, Value: &ast.StorageAccessStatement{Token: decl.Token, Name: decl.Name, Params: keys}}},
+	}
+}
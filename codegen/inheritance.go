@@ -0,0 +1,249 @@
+package codegen
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/polarysfoundation/ryot/ast"
+)
+
+// linearize calcula la linealización C3 de una clase: la propia clase seguida de
+// sus bases, de la más derivada a la más base. Como en Solidity, los padres se
+// declaran tras 'is' del más base al más derivado. Los errores llevan la posición
+// de la clase que los provoca.
+func (g *Generator) linearize(name string, visiting map[string]bool) ([]string, error) {
+	class := g.classes[name]
+	if visiting[name] {
+		return nil, g.withPosition(fmt.Errorf("codegen: herencia circular en '%s'", name), class)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	parents := slices.Clone(class.Parents)
+	slices.Reverse(parents)
+
+	sequences := make([][]string, 0, len(parents)+1)
+	for _, parent := range parents {
+		if _, ok := g.classes[parent]; !ok {
+			return nil, g.withPosition(fmt.Errorf("codegen: clase base no declarada '%s'", parent), class)
+		}
+		linearization, err := g.linearize(parent, visiting)
+		if err != nil {
+			return nil, err
+		}
+		sequences = append(sequences, linearization)
+	}
+	sequences = append(sequences, parents)
+
+	result := []string{name}
+	for {
+		pending := sequences[:0]
+		for _, seq := range sequences {
+			if len(seq) > 0 {
+				pending = append(pending, seq)
+			}
+		}
+		if len(pending) == 0 {
+			return result, nil
+		}
+
+		// La siguiente clase es la primera cabeza que no aparece en la cola de ninguna secuencia.
+		head := ""
+		for _, seq := range pending {
+			if !inTail(seq[0], pending) {
+				head = seq[0]
+				break
+			}
+		}
+		if head == "" {
+			return nil, g.withPosition(fmt.Errorf("codegen: no existe una linealización consistente para '%s'", name), class)
+		}
+
+		result = append(result, head)
+		for i, seq := range pending {
+			if seq[0] == head {
+				pending[i] = seq[1:]
+			}
+		}
+		sequences = pending
+	}
+}
+
+func inTail(name string, sequences [][]string) bool {
+	for _, seq := range sequences {
+		if slices.Contains(seq[1:], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten devuelve la clase con todos los miembros heredados según su linealización.
+// Comprueba que las redefiniciones usen 'override' con la misma firma y que un
+// contrato implemente todas las funciones de las interfaces que declara, ya sea
+// con una función o con el getter de una variable o un storage pub.
+func (g *Generator) flatten(class *ast.ClassStatement) (*ast.ClassStatement, error) {
+	if len(class.Parents) == 0 {
+		return class, nil
+	}
+
+	order, err := g.linearize(class.Name, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Funciones declaradas por las interfaces heredadas.
+	var interfaceFuncs []*ast.FuncStatement
+	interfaceOwner := map[*ast.FuncStatement]string{}
+	for _, name := range order[1:] {
+		base := g.classes[name]
+		if class.IsInterface && !base.IsInterface {
+			return nil, g.withPosition(fmt.Errorf("codegen: la interfaz '%s' solo puede heredar de interfaces, '%s' es un contrato", class.Name, name), class)
+		}
+		if !base.IsInterface {
+			continue
+		}
+		for _, stmt := range base.Body {
+			if fn, ok := stmt.(*ast.FuncStatement); ok {
+				interfaceFuncs = append(interfaceFuncs, fn)
+				interfaceOwner[fn] = name
+			}
+		}
+	}
+
+	flat := &ast.ClassStatement{
+		Token:       class.Token,
+		Name:        class.Name,
+		IsInterface: class.IsInterface,
+		Parents:     class.Parents,
+		Body:        []ast.Statement{},
+	}
+	members := map[string]int{}       // Nombre del miembro -> posición en flat.Body.
+	declaredIn := map[string]string{} // Nombre del miembro -> clase que lo declaró.
+	var constructorBody []ast.Statement
+	var constructor *ast.ConstructorStatement
+
+	for i := len(order) - 1; i >= 0; i-- {
+		current := g.classes[order[i]]
+		if current.IsInterface && !class.IsInterface {
+			continue // Las interfaces solo aportan firmas que comprobar.
+		}
+
+		for _, stmt := range current.Body {
+			if c, ok := stmt.(*ast.ConstructorStatement); ok {
+				if current != class {
+					if len(c.Params) > 0 {
+						return nil, g.withPosition(fmt.Errorf("codegen: el constructor heredado de '%s' no puede recibir parámetros", current.Name), c)
+					}
+					constructorBody = append(constructorBody, c.Body...)
+					continue
+				}
+				constructor = c
+				continue
+			}
+
+			name := memberName(stmt)
+			idx, exists := members[name]
+			fn, isFunc := stmt.(*ast.FuncStatement)
+
+			if isFunc && exists {
+				base, ok := flat.Body[idx].(*ast.FuncStatement)
+				if !ok {
+					return nil, g.withPosition(fmt.Errorf("codegen: la función '%s' de '%s' choca con otro miembro de '%s'", name, current.Name, declaredIn[name]), stmt)
+				}
+				if !fn.Override {
+					return nil, g.withPosition(fmt.Errorf("codegen: la función '%s' de '%s' redefine la de '%s' sin 'override'", name, current.Name, declaredIn[name]), stmt)
+				}
+				if !sameSignature(fn, base) {
+					return nil, g.withPosition(fmt.Errorf("codegen: la función '%s' de '%s' no tiene la misma firma que la de '%s'", name, current.Name, declaredIn[name]), stmt)
+				}
+				flat.Body[idx] = fn
+				declaredIn[name] = current.Name
+				continue
+			}
+			if exists {
+				return nil, g.withPosition(fmt.Errorf("codegen: '%s' se declara en '%s' y en '%s'", name, declaredIn[name], current.Name), stmt)
+			}
+			if isFunc && fn.Override && !overridesInterface(fn, interfaceFuncs) {
+				return nil, g.withPosition(fmt.Errorf("codegen: la función '%s' de '%s' usa 'override' pero no redefine ninguna función heredada", name, current.Name), stmt)
+			}
+
+			members[name] = len(flat.Body)
+			declaredIn[name] = current.Name
+			flat.Body = append(flat.Body, stmt)
+		}
+	}
+
+	// Los constructores de las bases se ejecutan antes que el del contrato derivado.
+	if constructor != nil || len(constructorBody) > 0 {
+		merged := &ast.ConstructorStatement{Body: constructorBody}
+		if constructor != nil {
+			merged.Token = constructor.Token
+			merged.Params = constructor.Params
+			merged.Body = append(slices.Clone(constructorBody), constructor.Body...)
+		}
+		flat.Body = append(flat.Body, merged)
+	}
+
+	if class.IsInterface {
+		return flat, nil
+	}
+
+	for _, required := range interfaceFuncs {
+		idx, ok := members[required.Name]
+		if !ok {
+			return nil, g.withPosition(fmt.Errorf("codegen: el contrato '%s' no implementa '%s.%s'", class.Name, interfaceOwner[required], required.Name), class)
+		}
+		impl, ok := flat.Body[idx].(*ast.FuncStatement)
+		if !ok {
+			// Una variable o un storage pub implementa la función con su getter.
+			impl = memberGetter(flat.Body[idx])
+			ok = impl != nil
+		}
+		if !ok || impl.Body == nil || !impl.Public {
+			return nil, g.withPosition(fmt.Errorf("codegen: el contrato '%s' no implementa '%s.%s'", class.Name, interfaceOwner[required], required.Name), class)
+		}
+		if !sameSignature(impl, required) {
+			return nil, g.withPosition(fmt.Errorf("codegen: '%s.%s' no tiene la firma de '%s.%s'", class.Name, impl.Name, interfaceOwner[required], required.Name), impl)
+		}
+	}
+
+	return flat, nil
+}
+
+// memberName devuelve el nombre con el que un miembro se hereda.
+func memberName(stmt ast.Statement) string {
+	switch s := stmt.(type) {
+	case *ast.FuncStatement:
+		return s.Name
+	case *ast.StorageDeclaration:
+		return s.Name
+	case *ast.VariableStatement:
+		return s.Name
+	case *ast.VariableStatementNonInitializer:
+		return s.Name
+	case *ast.ErrorStatement:
+		return s.Name
+	case *ast.ModifierStatement:
+		return s.Name
+	case *ast.EnumStatement:
+		return s.Name
+	case *ast.StructStatement:
+		return s.Name
+	}
+	return stmt.String()
+}
+
+// sameSignature indica si dos funciones tienen los mismos parámetros y tipo de retorno.
+func sameSignature(a, b *ast.FuncStatement) bool {
+	return slices.Equal(keyTypes(a.Params), keyTypes(b.Params)) && a.ReturnType.Type == b.ReturnType.Type
+}
+
+func overridesInterface(fn *ast.FuncStatement, interfaceFuncs []*ast.FuncStatement) bool {
+	for _, required := range interfaceFuncs {
+		if required.Name == fn.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/polarysfoundation/ryot/ast"
+)
+
+// Mutabilidad del estado de una función, de menor a mayor.
+const (
+	MutabilityPure       = "pure"       // No lee ni modifica el estado.
+	MutabilityView       = "view"       // Lee el estado o el contexto de la llamada, pero no lo modifica.
+	MutabilityNonPayable = "nonpayable" // Puede modificar el estado.
+	MutabilityPayable    = "payable"    // Puede modificar el estado y recibir moneda nativa.
+)
+
+// mutabilityLevel ordena las mutabilidades: cada una permite todo lo de las anteriores.
+var mutabilityLevel = map[string]int{
+	MutabilityPure:       0,
+	MutabilityView:       1,
+	MutabilityNonPayable: 2,
+	MutabilityPayable:    3,
+}
+
+// effect es el acceso al estado de mayor nivel que hace una función.
+type effect struct {
+	level  string
+	node   ast.Node // Sentencia que produce el acceso, para la posición del error.
+	reason string   // Descripción del acceso en los mensajes de error.
+}
+
+// inferMutability deduce la mutabilidad de cada función de class a partir de sus
+// lecturas y escrituras del storage, de las de sus modificadores y de las de las
+// funciones a las que llama, y comprueba que las funciones declaradas view o pure
+// no hagan más de lo que declaran.
+func (g *Generator) inferMutability(class *ast.ClassStatement) error {
+	var funcs []*ast.FuncStatement
+	g.mutability = make(map[string]string)
+	for _, stmt := range class.Body {
+		if fn, ok := stmt.(*ast.FuncStatement); ok && fn.Body != nil {
+			funcs = append(funcs, fn)
+			g.mutability[fn.Name] = MutabilityPure
+		}
+	}
+
+	// Las llamadas internas pueden ser recursivas: se repite hasta que ninguna
+	// función cambia de nivel.
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range funcs {
+			if e := g.functionEffect(fn); mutabilityLevel[e.level] > mutabilityLevel[g.mutability[fn.Name]] {
+				g.mutability[fn.Name] = e.level
+				changed = true
+			}
+		}
+	}
+
+	for _, fn := range funcs {
+		if fn.Mutability == "" {
+			continue
+		}
+		if e := g.functionEffect(fn); mutabilityLevel[e.level] > mutabilityLevel[fn.Mutability] {
+			err := fmt.Errorf("codegen: la función '%s' se declaró %s pero %s", fn.Name, fn.Mutability, e.reason)
+			return g.withPosition(err, e.node)
+		}
+		g.mutability[fn.Name] = fn.Mutability
+	}
+	return nil
+}
+
+// functionEffect devuelve el primer acceso de mayor nivel de fn, contando los
+// cuerpos de sus modificadores.
+func (g *Generator) functionEffect(fn *ast.FuncStatement) effect {
+	result := effect{level: MutabilityPure}
+	visit := func(body []ast.Statement, scope map[string]bool, at func(ast.Statement) ast.Node) {
+		for _, stmt := range body {
+			ast.Inspect(stmt, func(node ast.Node) bool {
+				level, reason := g.nodeEffect(node, scope)
+				if mutabilityLevel[level] > mutabilityLevel[result.level] {
+					result = effect{level: level, node: at(stmt), reason: reason}
+				}
+				return true
+			})
+		}
+	}
+
+	for _, inv := range fn.Modifiers {
+		mod, ok := g.modifiers[inv.Name]
+		if !ok {
+			continue // generateModified informa del modificador no declarado.
+		}
+		// Los accesos de un modificador se señalan en la función que lo usa.
+		visit(mod.Body, localScope(mod.Params, mod.Body), func(ast.Statement) ast.Node { return fn })
+	}
+	visit(fn.Body, localScope(fn.Params, fn.Body), func(stmt ast.Statement) ast.Node { return stmt })
+	return result
+}
+
+// localScope devuelve los nombres de los parámetros y las constantes locales de
+// body, que ocultan a las variables del contrato.
+func localScope(params []ast.Key, body []ast.Statement) map[string]bool {
+	scope := make(map[string]bool)
+	for _, param := range params {
+		scope[param.Name] = true
+	}
+	for _, stmt := range body {
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			if local, ok := node.(*ast.ConstExpression); ok {
+				scope[local.Name] = true
+			}
+			return true
+		})
+	}
+	return scope
+}
+
+// nodeEffect devuelve el nivel de mutabilidad que exige node y su descripción.
+func (g *Generator) nodeEffect(node ast.Node, scope map[string]bool) (string, string) {
+	switch n := node.(type) {
+	case *ast.StorageStatement:
+		if n.Value != nil {
+			return MutabilityNonPayable, fmt.Sprintf("escribe en el storage '%s'", n.Name)
+		}
+		return MutabilityView, fmt.Sprintf("lee el storage '%s'", n.Name)
+	case *ast.NewStatement:
+		return MutabilityNonPayable, fmt.Sprintf("escribe en el storage '%s'", n.Name)
+	case *ast.DeleteStatement:
+		return MutabilityNonPayable, fmt.Sprintf("borra del storage '%s'", n.Name)
+	case *ast.StorageAccessStatement:
+		if _, ok := g.storages[n.Name]; ok {
+			return MutabilityView, fmt.Sprintf("lee el storage '%s'", n.Name)
+		}
+		if level, ok := g.mutability[n.Name]; ok && level != MutabilityPure {
+			return level, fmt.Sprintf("llama a '%s', que es %s", n.Name, level)
+		}
+	case *ast.AssignStatement:
+		return MutabilityNonPayable, fmt.Sprintf("escribe en la variable '%s'", n.Name)
+	case *ast.Identifier:
+		if _, constant := g.constants[n.Value]; constant {
+			break // Las constantes se conocen en compilación.
+		}
+		if _, ok := g.variables[n.Value]; ok && !scope[n.Value] {
+			return MutabilityView, fmt.Sprintf("lee la variable '%s'", n.Value)
+		}
+	case *ast.MemberExpression:
+		if object, ok := n.Object.(*ast.Identifier); ok && object.Value == "block" {
+			return MutabilityView, fmt.Sprintf("lee %s", n.String())
+		}
+	case *ast.CallExpression:
+		if fn, ok := n.Function.(*ast.Identifier); ok {
+			if _, ok := g.lookupBuiltin(fn.Value); !ok {
+				break
+			}
+			switch fn.Value {
+			case "selfdestruct", "transfer":
+				return MutabilityNonPayable, fmt.Sprintf("llama a %s()", fn.Value)
+			case "caller", "self", "balanceOf", "value":
+				return MutabilityView, fmt.Sprintf("lee el contexto con %s()", fn.Value)
+			}
+		}
+	case *ast.CreateExpression:
+		return MutabilityNonPayable, fmt.Sprintf("despliega el contrato '%s'", n.Contract)
+	case *ast.ExternalCallExpression:
+		if fn, err := g.interfaceFunction(n); err == nil && (fn.Mutability == MutabilityView || fn.Mutability == MutabilityPure) {
+			return MutabilityView, fmt.Sprintf("llama a %s.%s", n.Interface, n.Method)
+		}
+		return MutabilityNonPayable, fmt.Sprintf("llama a %s.%s, que puede modificar el estado", n.Interface, n.Method)
+	}
+	return MutabilityPure, ""
+}
@@ -27,7 +27,7 @@ const (
 	OpJumpDest // 0x22 - Marca un destino de salto
 	OpCall     // 0x23 - Llama a una función
 	OpReturn   // 0x24 - Retorna de una función
-	OpRevert   // 0x25 - Revierte la ejecución de la transacción
+	OpRevert   // 0x25 - Revierte la ejecución con un error personalizado (selector + argumentos)
 
 	// Funciones y contratos
 	OpContract // 0x30 - Define el inicio de un contrato
@@ -40,10 +40,10 @@ const (
 	OpEnum   // 0x42 - Operación relacionada con enums
 
 	// Operaciones específicas de blockchain
-	OpAddress // 0x50 - Carga la dirección del contrato o de una entidad
+	OpAddress // 0x50 - Carga una dirección literal o, sin argumentos, la dirección del propio contrato
 	OpBalance // 0x51 - Carga el balance de una dirección
 	OpCaller  // 0x52 - Carga la dirección del llamador
-	OpHash    // 0x53 - Calcula el hash de un valor
+	OpHash    // 0x53 - Calcula el pm-256 de los N valores superiores de la pila (codificación canónica) o carga un hash literal
 
 	// Metadatos
 	OpMeta // 0x60 - Operación para metadatos del programa
@@ -75,6 +75,31 @@ const (
 
 	OpZeroHash // 0xFA - hash de 32 bytes con valor cero (utilizado para inicializar variables o como valor por defecto en estructuras de datos)
 	OpZeroAddr // 0xF9 - address con valor cero
+
+	// Despliegue
+	OpConstructor // 0xF8 - Define el inicio del constructor (solo en el código de inicialización)
+	OpDeploy      // 0xF7 - Finaliza el despliegue e instala el código de runtime
+
+	// Contexto del bloque
+	OpBlockNumber // 0xF6 - Carga el número del bloque actual
+	OpTimestamp   // 0xF5 - Carga la marca de tiempo del bloque actual
+
+	// Llamadas entre contratos
+	OpExtCall // 0xF4 - Llama a otro contrato: dirección y argumentos en la pila, selector y tipo de retorno como operandos
+
+	// Creación de contratos
+	OpEmbed // 0xF3 - Inicia una sección con el código de inicialización y de runtime de un contrato que se puede crear
+
+	// Moneda nativa
+	OpCallValue // 0xF2 - Carga la cantidad de moneda nativa enviada con la llamada
+	OpTransfer  // 0xF1 - Transfiere moneda nativa del contrato: dirección y cantidad en la pila
+
+	// Inmutables
+	OpSetImmutable // 0xF0 - Fija durante el despliegue el valor de un inmutable, que DEPLOY incrusta en el código de runtime
+	OpImmutable    // 0xEF - Carga el valor de un inmutable incrustado en el código de runtime
+
+	// Llamadas externas
+	OpEntry // 0xEE - Inicia el prólogo que se ejecuta solo cuando la función se llama desde fuera del contrato
 )
 
 // Instruction representa una única instrucción de bytecode.
@@ -177,6 +202,28 @@ func (o Opcode) String() string {
 		return "DUP"
 	case OpSwap:
 		return "SWAP"
+	case OpConstructor:
+		return "CONSTRUCTOR"
+	case OpDeploy:
+		return "DEPLOY"
+	case OpBlockNumber:
+		return "BLOCKNUMBER"
+	case OpTimestamp:
+		return "TIMESTAMP"
+	case OpExtCall:
+		return "EXTCALL"
+	case OpEmbed:
+		return "EMBED"
+	case OpCallValue:
+		return "CALLVALUE"
+	case OpTransfer:
+		return "TRANSFER"
+	case OpSetImmutable:
+		return "SETIMMUTABLE"
+	case OpImmutable:
+		return "IMMUTABLE"
+	case OpEntry:
+		return "ENTRY"
 	default:
 		return fmt.Sprintf("UNKNOWN_OPCODE(0x%x)", byte(o))
 	}
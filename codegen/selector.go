@@ -0,0 +1,30 @@
+package codegen
+
+import (
+	"encoding/hex"
+	"strings"
+
+	pm256 "github.com/polarysfoundation/pm-256"
+)
+
+// Selector identifica una función o un error personalizado mediante los
+// primeros 4 bytes del hash pm-256 de su firma canónica.
+type Selector [4]byte
+
+// Signature construye la firma canónica "Nombre(tipo1,tipo2)".
+func Signature(name string, types []string) string {
+	return name + "(" + strings.Join(types, ",") + ")"
+}
+
+// NewSelector calcula el selector de la firma formada por el nombre y los tipos.
+func NewSelector(name string, types []string) Selector {
+	var s Selector
+	sum := pm256.Sum256([]byte(Signature(name, types)))
+	copy(s[:], sum[:4])
+	return s
+}
+
+// String devuelve el selector en hexadecimal con prefijo 0x.
+func (s Selector) String() string {
+	return "0x" + hex.EncodeToString(s[:])
+}
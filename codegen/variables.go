@@ -0,0 +1,99 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/polarysfoundation/ryot/ast"
+)
+
+// checkConstants comprueba que el valor de cada constante del contrato tenga el
+// tipo declarado y se pueda calcular en compilación.
+func (g *Generator) checkConstants(class *ast.ClassStatement) error {
+	for _, stmt := range class.Body {
+		decl, ok := stmt.(*ast.VariableStatement)
+		if !ok || decl.Kind != ast.KindConst {
+			continue
+		}
+		if err := g.checkConstant(decl.Name, decl.Value, map[string]bool{decl.Name: true}); err != nil {
+			return g.withPosition(err, decl)
+		}
+		if valueType := g.typeOf(decl.Value); valueType != "" && valueType != decl.Token.Literal {
+			return g.withPosition(fmt.Errorf("codegen: no se puede asignar %s a '%s' de tipo %s", valueType, decl.Name, decl.Token.Literal), decl)
+		}
+	}
+	return nil
+}
+
+// checkConstant comprueba que expr, el valor de la constante name, solo use
+// literales, otras constantes y operaciones entre ellos. visiting contiene las
+// constantes que se están comprobando, para detectar las que dependen de sí mismas.
+func (g *Generator) checkConstant(name string, expr ast.Expression, visiting map[string]bool) error {
+	switch n := expr.(type) {
+	case *ast.IntegerLiteral, *ast.BooleanLiteral, *ast.StringLiteral, *ast.ByteLiteral, *ast.AddressExpression, *ast.HashLiteral:
+		return nil
+	case *ast.BinaryExpression:
+		if err := g.checkConstant(name, n.Left, visiting); err != nil {
+			return err
+		}
+		return g.checkConstant(name, n.Right, visiting)
+	case *ast.Identifier:
+		value, ok := g.constants[n.Value]
+		if !ok {
+			break
+		}
+		if visiting[n.Value] {
+			return fmt.Errorf("codegen: la constante '%s' depende de sí misma", name)
+		}
+		visiting[n.Value] = true
+		defer delete(visiting, n.Value)
+		return g.checkConstant(name, value, visiting)
+	}
+	return fmt.Errorf("codegen: el valor de la constante '%s' no se conoce en compilación", name)
+}
+
+// generateAssign genera la asignación de una variable del contrato. Las
+// constantes no se pueden asignar y los inmutables solo una vez, en el constructor.
+func (g *Generator) generateAssign(n *ast.AssignStatement) error {
+	if _, local := g.scope[g.localName(n.Name)]; local {
+		return fmt.Errorf("codegen: '%s' es un parámetro o una constante local y no se puede asignar", n.Name)
+	}
+	varType, ok := g.variables[n.Name]
+	if !ok {
+		return fmt.Errorf("codegen: variable no declarada '%s'", n.Name)
+	}
+	if _, constant := g.constants[n.Name]; constant {
+		return fmt.Errorf("codegen: no se puede asignar la constante '%s'", n.Name)
+	}
+	if valueType := g.typeOf(n.Value); valueType != "" && valueType != varType {
+		return fmt.Errorf("codegen: no se puede asignar %s a '%s' de tipo %s", valueType, n.Name, varType)
+	}
+
+	if !g.immutables[n.Name] {
+		g.emit(OpStore, n.Name)
+		if err := g.Generate(n.Value); err != nil {
+			return err
+		}
+		g.emit(OpEnd, "STORE")
+		return nil
+	}
+	if g.assigned == nil {
+		return fmt.Errorf("codegen: el inmutable '%s' solo puede asignarse en el constructor", n.Name)
+	}
+	if g.assigned[n.Name] {
+		return fmt.Errorf("codegen: el inmutable '%s' ya tiene valor y no se puede reasignar", n.Name)
+	}
+	return g.generateSetImmutable(n.Name, n.Value)
+}
+
+// generateSetImmutable fija el valor de un inmutable en el código de
+// inicialización. El inmutable cuenta como asignado después de su valor, que
+// no puede leerlo.
+func (g *Generator) generateSetImmutable(name string, value ast.Expression) error {
+	g.emit(OpSetImmutable, name)
+	if err := g.Generate(value); err != nil {
+		return err
+	}
+	g.emit(OpEnd, "IMMUTABLE")
+	g.assigned[name] = true
+	return nil
+}
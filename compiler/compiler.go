@@ -2,48 +2,89 @@ package compiler
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt" // Importa fmt para el manejo de errores
-	"os"
+	"path/filepath"
 
 	pm256 "github.com/polarysfoundation/pm-256"
 	"github.com/polarysfoundation/ryot/ast"
 	"github.com/polarysfoundation/ryot/codegen"
+	"github.com/polarysfoundation/ryot/doc"
+	"github.com/polarysfoundation/ryot/flow"
 	"github.com/polarysfoundation/ryot/lexer"
 	"github.com/polarysfoundation/ryot/parser"
+	"github.com/polarysfoundation/ryot/version"
 )
 
-const (
-	path = "./artifacts/"
-)
+const path = "./artifacts/"
+
+// Version es la versión del compilador; el pragma de cada programa debe admitirla.
+var Version = version.Compiler.String()
 
-// CompiledContract representa el resultado de la compilación de un contrato.
+// CompiledContract representa el resultado de la compilación de una clase.
 type CompiledContract struct {
-	Version  string                // Versión del compilador o del formato de bytecode.
-	Bytecode []codegen.Instruction // Las instrucciones de bytecode generadas.
-	ABI      codegen.ABI           // La Interfaz Binaria de Aplicación del contrato.
+	Name         string                 // Nombre del contrato o de la interfaz.
+	Version      string                 // Versión del compilador o del formato de bytecode.
+	IsInterface  bool                   // Las interfaces solo tienen ABI.
+	InitBytecode []codegen.Instruction  // Código de inicialización: variables y constructor, se ejecuta al desplegar.
+	Bytecode     []codegen.Instruction  // Código de runtime que queda instalado tras el despliegue.
+	ABI          codegen.ABI            // La Interfaz Binaria de Aplicación del contrato.
+	Storage      []codegen.StorageEntry // Distribución del almacenamiento, en orden de declaración.
+	Imported     bool                   // La clase viene de un archivo importado, no del programa compilado.
+	Doc          *doc.Contract          // Documentación extraída de los comentarios ///.
+	Warnings     []Diagnostic           // Avisos de la clase, como el código inalcanzable.
+	Artifacts    map[string][]byte      // Artefactos generados, por nombre de archivo (abi.json, bytecode.rybc...).
+}
+
+// Compile toma el código fuente como entrada y compila cada clase por separado,
+// generando su bytecode, su ABI y sus archivos de salida en ./artifacts/<Nombre>/.
+// Devuelve los contratos e interfaces compilados indexados por nombre. Las
+// importaciones relativas se resuelven desde el directorio de trabajo.
+func Compile(input string) (map[string]*CompiledContract, error) {
+	return CompileWithOptions(input, Options{})
 }
 
-// Compile toma el código fuente de un contrato como entrada y lo compila,
-// generando bytecode, ABI y archivos de salida.
-func Compile(input string) (*CompiledContract, error) {
-	l := lexer.New(input)
-	p := parser.New(l)
-	programNode := p.ParseProgram() // Asume que ParseProgram ya maneja sus propios errores o los propaga.
+// CompileFile compila el archivo indicado. Sus importaciones relativas se resuelven
+// desde el directorio del archivo y el resto se buscan en searchPath.
+func CompileFile(filename string, searchPath ...string) (map[string]*CompiledContract, error) {
+	return CompileFileWithOptions(filename, Options{SearchPath: searchPath})
+}
+
+// CompileFileWithOptions compila el archivo indicado según las opciones. El
+// archivo se lee, como sus importaciones, del FileSystem de las opciones.
+func CompileFileWithOptions(filename string, opts Options) (map[string]*CompiledContract, error) {
+	opts.Filename = filename
+	opts = opts.withDefaults()
+	input, err := opts.FS.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", filename, err)
+	}
+	return CompileWithOptions(string(input), opts)
+}
+
+// CompileWithOptions compila input según las opciones indicadas. Los artefactos
+// seleccionados se devuelven siempre en CompiledContract.Artifacts y, salvo en
+// modo en memoria, se escriben además en OutputDir/<Nombre>/.
+func CompileWithOptions(input string, opts Options) (map[string]*CompiledContract, error) {
+	opts = opts.withDefaults()
+	resolver := &Resolver{SearchPath: opts.SearchPath, FS: opts.FS, parserOptions: opts.parserOptions()}
+
+	l := lexer.New(input, lexer.WithComments()) // Los comentarios /// son la documentación.
+	p := parser.New(l, opts.parserOptions()...)
+	programNode := p.ParseProgram()
 	program, ok := programNode.(*ast.Program)
 	if !ok {
 		return nil, fmt.Errorf("parser did not return *ast.Program")
 	}
+	if len(p.SyntaxErrors()) > 0 {
+		return nil, syntaxDiagnostics(opts.Filename, p.SyntaxErrors())
+	}
 
 	buf := make([]byte, 32)
 	h := pm256.New256()
 	h.Write([]byte(input))
 	h.Sum(buf[:0])
 
-	if _, err := os.Stat(path); err == nil {
-		os.RemoveAll(path)
-	}
-	os.MkdirAll(path, os.ModePerm)
-
 	// Verifica si el primer statement es un PragmaStatement y obtiene la versión.
 	// Esto asume que el primer statement SIEMPRE será el pragma.
 	// Deberías añadir una verificación para asegurarte de que es un PragmaStatement.
@@ -55,7 +96,15 @@ func Compile(input string) (*CompiledContract, error) {
 	if !ok {
 		return nil, fmt.Errorf("expected first statement to be a pragma, got %T", program.Statements[0])
 	}
-	compilerVersion := pragmaStmt.Value
+	if err := checkPragma(opts.Filename, pragmaStmt); err != nil {
+		return nil, err
+	}
+
+	// Las importaciones se sustituyen por las clases que traen.
+	program, imported, err := resolver.resolveImports(program, opts.Filename)
+	if err != nil {
+		return nil, err
+	}
 
 	// Verifica si hay al menos un ClassStatement (el contrato principal).
 	foundContract := false
@@ -70,41 +119,147 @@ func Compile(input string) (*CompiledContract, error) {
 
 	}
 
-	compiler := &CompiledContract{
-		Version: "1.0.0", // Mantén la versión consistente, o lée la del `codegen`.
-	}
-
-	// Si el parser tiene errores, deberías verificarlo aquí.
-	if compilerVersion != compiler.Version {
-		return nil, fmt.Errorf("compiler version mismatch: expected %s, got %s", compiler.Version, pragmaStmt.Value)
-	}
-
-	if compilerVersion != compiler.Version {
-		return nil, fmt.Errorf("compiler version mismatch: expected %s, got %s", compiler.Version, program.Statements[0].(*ast.PragmaStatement).Value)
+	// Las funciones que pueden terminar sin return no se compilan.
+	errs, warnings := flowDiagnostics(flow.Check(program), opts.Filename, imported)
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
 	g := codegen.New()
 
-	fmt.Println(programNode)
+	opts.Logger.Printf("compilando %s: %d declaraciones", opts.Filename, len(program.Statements))
 
 	// La función Generate ahora devuelve un error.
 	if err := g.Generate(ast.Node(program)); err != nil {
-		return nil, fmt.Errorf("error de generación de código: %w", err)
+		return nil, codegenDiagnostic(err, opts.Filename, imported)
+	}
+
+	docs, err := doc.Extract(program)
+	if err != nil {
+		return nil, docDiagnostic(err, opts.Filename, imported)
+	}
+
+	contracts := make(map[string]*CompiledContract)
+	for _, contract := range g.GetContracts() {
+		artifacts, err := encodeArtifacts(contract, docs[contract.Name], opts.Artifacts, buf)
+		if err != nil {
+			return nil, err
+		}
+		if !opts.InMemory {
+			dir := filepath.Join(opts.OutputDir, contract.Name)
+			if err := writeArtifacts(opts.FS, dir, artifacts); err != nil {
+				return nil, err
+			}
+			opts.Logger.Printf("%s: %d artefactos escritos en %s", contract.Name, len(artifacts), dir)
+		}
+		contracts[contract.Name] = &CompiledContract{
+			Name:         contract.Name,
+			Version:      Version,
+			IsInterface:  contract.IsInterface,
+			InitBytecode: contract.InitCode,
+			Bytecode:     contract.Instructions,
+			ABI:          contract.ABI,
+			Storage:      contract.Storage,
+			Imported:     imported[contract.Name] != "",
+			Doc:          docs[contract.Name],
+			Warnings:     warnings[contract.Name],
+			Artifacts:    artifacts,
+		}
 	}
 
-	// Manejo de errores para la escritura de archivos.
-	if err := g.WriteABI(path + "abi.json"); err != nil {
-		return nil, fmt.Errorf("error al escribir ABI: %w", err)
+	return contracts, nil
+}
+
+// checkPragma comprueba que el rango de versiones del pragma de file admita este
+// compilador. Si no lo admite, el diagnóstico sugiere un compilador compatible.
+func checkPragma(file string, pragma *ast.PragmaStatement) error {
+	diag := Diagnostic{Severity: SeverityError, File: file, Line: pragma.Token.Line, Column: pragma.Token.Column}
+
+	constraint, err := version.ParseConstraint(pragma.Value)
+	if err != nil {
+		diag.Message = fmt.Sprintf("compiler: pragma inválido \"%s\": %v", pragma.Value, err)
+		return diag
 	}
-	if err := g.WriteRYC(path+"bytecode.ryc", hex.EncodeToString(buf)); err != nil {
-		return nil, fmt.Errorf("error al escribir RYC: %w", err)
+	if constraint.Check(version.Compiler) {
+		return nil
 	}
-	if err := g.WriteRYBC(path+"bytecode.rybc", buf); err != nil {
-		return nil, fmt.Errorf("error al escribir RYBC: %w", err)
+
+	diag.Message = fmt.Sprintf("compiler: el pragma \"%s\" no admite la versión %s del compilador", pragma.Value, Version)
+	if minimum, ok := constraint.Minimum(); ok {
+		diag.Message += fmt.Sprintf("; use un compilador compatible con el rango (p. ej. %s) o cambie el pragma a \"^%s\"", minimum, Version)
+	} else {
+		diag.Message += fmt.Sprintf("; ninguna versión cumple el rango, cambie el pragma a \"^%s\"", Version)
 	}
+	return diag
+}
 
-	compiler.Bytecode = g.GetInstructions()
-	compiler.ABI = g.GetABI()
+// encodeArtifacts serializa los artefactos seleccionados de una clase, indexados
+// por nombre de archivo. Las interfaces solo tienen la ABI y la documentación.
+func encodeArtifacts(contract *codegen.Contract, docs *doc.Contract, selected Artifacts, codehash []byte) (map[string][]byte, error) {
+	artifacts := make(map[string][]byte)
 
-	return compiler, nil
+	if selected&ArtifactABI != 0 {
+		abi, err := contract.EncodeABI()
+		if err != nil {
+			return nil, fmt.Errorf("error al serializar ABI de %s: %w", contract.Name, err)
+		}
+		artifacts["abi.json"] = abi
+	}
+	if docs != nil {
+		if selected&ArtifactUserDoc != 0 {
+			userdoc, err := json.MarshalIndent(docs.UserDoc(), "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("error al serializar userdoc de %s: %w", contract.Name, err)
+			}
+			artifacts["userdoc.json"] = userdoc
+		}
+		if selected&ArtifactDevDoc != 0 {
+			devdoc, err := json.MarshalIndent(docs.DevDoc(), "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("error al serializar devdoc de %s: %w", contract.Name, err)
+			}
+			artifacts["devdoc.json"] = devdoc
+		}
+		if selected&ArtifactMarkdown != 0 {
+			artifacts["doc.md"] = docs.Markdown()
+		}
+	}
+	if contract.IsInterface {
+		return artifacts, nil // Las interfaces no generan bytecode.
+	}
+	if selected&ArtifactRYC != 0 {
+		artifacts["bytecode.ryc"] = contract.EncodeRYC(hex.EncodeToString(codehash))
+	}
+	if selected&ArtifactRYBC != 0 {
+		rybc, err := contract.EncodeRYBC(codehash)
+		if err != nil {
+			return nil, fmt.Errorf("error al serializar RYBC de %s: %w", contract.Name, err)
+		}
+		artifacts["bytecode.rybc"] = rybc
+	}
+	if selected&ArtifactInitRYC != 0 {
+		artifacts["init.ryc"] = contract.EncodeInitRYC(hex.EncodeToString(codehash))
+	}
+	if selected&ArtifactInitRYBC != 0 {
+		rybc, err := contract.EncodeInitRYBC(codehash)
+		if err != nil {
+			return nil, fmt.Errorf("error al serializar RYBC de inicialización de %s: %w", contract.Name, err)
+		}
+		artifacts["init.rybc"] = rybc
+	}
+	return artifacts, nil
+}
+
+// writeArtifacts escribe los artefactos de una clase en su propio directorio. Solo
+// se reemplazan esos archivos: el resto del directorio de salida no se toca.
+func writeArtifacts(fsys FileSystem, dir string, artifacts map[string][]byte) error {
+	if err := fsys.MkdirAll(dir); err != nil {
+		return fmt.Errorf("error al crear el directorio %s: %w", dir, err)
+	}
+	for name, data := range artifacts {
+		if err := fsys.WriteFile(filepath.Join(dir, name), data); err != nil {
+			return fmt.Errorf("error al escribir %s: %w", filepath.Join(dir, name), err)
+		}
+	}
+	return nil
 }
@@ -1,11 +1,17 @@
 package compiler
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	pm256 "github.com/polarysfoundation/pm-256"
+	"github.com/polarysfoundation/ryot/codegen"
 	"github.com/polarysfoundation/ryot/lexer"
 	"github.com/polarysfoundation/ryot/parser"
 )
@@ -29,11 +35,1720 @@ func TestCompiler(t *testing.T) {
 
 	
 
-	contract, err := Compile(string(input))
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	fmt.Println(contract)
+	fmt.Println(contracts)
 
 }
+
+func TestCompileCustomErrors(t *testing.T) {
+	input, err := os.ReadFile("../example/errors.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Vault"]
+
+	selector := codegen.NewSelector("InsufficientBalance", []string{"uint64", "uint64"})
+
+	found := false
+	for _, entry := range contract.ABI {
+		if entry.Type == "error" && entry.Name == "InsufficientBalance" {
+			found = true
+			if entry.Selector != selector.String() {
+				t.Fatalf("expected selector %s, got %s", selector, entry.Selector)
+			}
+			if len(entry.Inputs) != 2 || entry.Inputs[0].Name != "needed" {
+				t.Fatalf("unexpected error inputs: %+v", entry.Inputs)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("error InsufficientBalance missing from ABI")
+	}
+
+	reverts := 0
+	for _, instr := range contract.Bytecode {
+		if instr.Opcode == codegen.OpRevert {
+			reverts++
+		}
+	}
+	if reverts != 2 {
+		t.Fatalf("expected 2 REVERT instructions, got %d", reverts)
+	}
+}
+
+func TestCompileUndeclaredError(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Vault {
+		pub func deny(): void {
+			revert Missing();
+		}
+	}
+	`
+	if _, err := CompileWithOptions(input, Options{InMemory: true}); err == nil {
+		t.Fatalf("expected an error for an undeclared custom error")
+	}
+}
+
+func TestCompileErrorArgumentTypes(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Vault {
+		error E(code: uint64);
+
+		pub func deny(): void {
+			revert E(caller());
+		}
+	}
+	`
+	_, err := CompileWithOptions(input, Options{InMemory: true})
+	if err == nil || !strings.Contains(err.Error(), "el argumento 1 del error 'E' debe ser uint64, recibió address") {
+		t.Fatalf("expected a mistyped error argument diagnostic, got %v", err)
+	}
+}
+
+func TestCompileConstructor(t *testing.T) {
+	input, err := os.ReadFile("../example/constructor.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Counter"]
+
+	hasOpcode := func(code []codegen.Instruction, op codegen.Opcode, arg interface{}) bool {
+		for _, instr := range code {
+			if instr.Opcode == op && (arg == nil || (len(instr.Args) > 0 && instr.Args[0] == arg)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !hasOpcode(contract.InitBytecode, codegen.OpStore, "initalized_count") {
+		t.Fatalf("variable initializer missing from init code")
+	}
+	if hasOpcode(contract.Bytecode, codegen.OpStore, "initalized_count") {
+		t.Fatalf("variable initializer must not be part of the runtime code")
+	}
+	if !hasOpcode(contract.InitBytecode, codegen.OpConstructor, nil) || !hasOpcode(contract.InitBytecode, codegen.OpDeploy, nil) {
+		t.Fatalf("init code must run the constructor and deploy the runtime code")
+	}
+	if hasOpcode(contract.Bytecode, codegen.OpConstructor, nil) {
+		t.Fatalf("constructor must not be part of the runtime code")
+	}
+
+	if contract.ABI[0].Type != "constructor" || len(contract.ABI[0].Inputs) != 1 {
+		t.Fatalf("expected a constructor ABI entry, got %+v", contract.ABI[0])
+	}
+}
+
+func TestCompileModifiers(t *testing.T) {
+	input, err := os.ReadFile("../example/modifiers.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Treasury"]
+
+	// Both guards are inlined before the function body, in application order.
+	var order []codegen.Opcode
+	inFunc := false
+	for _, instr := range contract.Bytecode {
+		switch {
+		case instr.Opcode == codegen.OpFunc:
+			inFunc = instr.Args[0] == "withdraw"
+		case instr.Opcode == codegen.OpEnd && instr.Args[0] == "FUNC":
+			inFunc = false
+		}
+		if !inFunc {
+			continue
+		}
+		switch instr.Opcode {
+		case codegen.OpRevert, codegen.OpErr:
+			order = append(order, instr.Opcode)
+		case codegen.OpStore:
+			if instr.Args[0] == "limits" {
+				order = append(order, instr.Opcode)
+			}
+		}
+	}
+	expected := []codegen.Opcode{codegen.OpRevert, codegen.OpErr, codegen.OpStore}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Fatalf("expected inlined order %v, got %v", expected, order)
+	}
+}
+
+func TestCompileModifierWithoutPlaceholder(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Treasury {
+		modifier broken() {
+			check(true, err: "never");
+		}
+
+		pub func withdraw() broken: void {
+		}
+	}
+	`
+	if _, err := CompileWithOptions(input, Options{InMemory: true}); err == nil {
+		t.Fatalf("expected an error for a modifier without '_;'")
+	}
+}
+
+func TestCompileContextualKeywordNames(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Token {
+		storage balances(address): uint64;
+
+		pub func transfer(from: address, to: address, amount: uint64): void {
+			balances(from): balances(from) - amount;
+			balances(to): balances(to) + amount;
+		}
+	}
+	`
+	contracts, err := CompileWithOptions(input, Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range contracts["Token"].ABI {
+		if entry.Name == "transfer" && len(entry.Inputs) != 3 {
+			t.Fatalf("expected from to be a parameter, got inputs %+v", entry.Inputs)
+		}
+	}
+
+	_, err = CompileWithOptions(strings.Replace(input, "from", "return", -1), Options{InMemory: true})
+	if err == nil || !strings.Contains(err.Error(), "expected identifier, got keyword return") {
+		t.Fatalf("expected a keyword error, got %v", err)
+	}
+}
+
+func TestCompileModifierScope(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Registry {
+		modifier only(who: address) {
+			address expected: who;
+			check(expected == caller(), err: "unauthorized");
+			_;
+		}
+
+		pub func f(who: address) only(self()): address {
+			address expected: who;
+			return expected;
+		}
+	}
+	`
+	contracts, err := CompileWithOptions(input, Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The modifier's parameter and local must not shadow the function's, which
+	// share their names.
+	var consts, loads []interface{}
+	inFunc := false
+	for _, instr := range contracts["Registry"].Bytecode {
+		switch {
+		case instr.Opcode == codegen.OpFunc:
+			inFunc = instr.Args[0] == "f"
+		case !inFunc:
+		case instr.Opcode == codegen.OpConst && len(instr.Args) == 1:
+			if name, ok := instr.Args[0].(string); ok && name != "unauthorized" {
+				consts = append(consts, name)
+			}
+		case instr.Opcode == codegen.OpLoad:
+			loads = append(loads, instr.Args[0])
+		}
+	}
+	wantConsts := []interface{}{"__mod_only_1_who", "__mod_only_1_expected", "expected"}
+	wantLoads := []interface{}{"__mod_only_1_who", "__mod_only_1_expected", "who", "expected"}
+	if fmt.Sprint(consts) != fmt.Sprint(wantConsts) || fmt.Sprint(loads) != fmt.Sprint(wantLoads) {
+		t.Fatalf("unexpected locals: consts %v, loads %v", consts, loads)
+	}
+}
+
+func TestCompileContextBuiltins(t *testing.T) {
+	input, err := os.ReadFile("../example/context.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Context"]
+
+	seen := map[codegen.Opcode]bool{}
+	for _, instr := range contract.Bytecode {
+		seen[instr.Opcode] = true
+	}
+	for _, op := range []codegen.Opcode{codegen.OpCaller, codegen.OpAddress, codegen.OpBalance, codegen.OpBlockNumber, codegen.OpTimestamp} {
+		if !seen[op] {
+			t.Fatalf("expected %s in the runtime code", op)
+		}
+	}
+}
+
+func TestCompileContextBuiltinTypes(t *testing.T) {
+	tests := map[string]string{
+		"argument": "pub func f(): uint64 { return balanceOf(1); }",
+		"arity":    "pub func f(): address { return caller(1cxdc6e0e801fbe5ae5f2799361d34b53); }",
+		"compare":  "pub func f(): bool { return caller() == block.number; }",
+		"return":   "pub func f(): uint64 { return caller(); }",
+		"member":   "pub func f(): uint64 { return block.gas; }",
+	}
+
+	for name, fn := range tests {
+		input := `pragma: "1.0.0";
+		class contract Context {
+			` + fn + `
+		}
+		`
+		if _, err := CompileWithOptions(input, Options{InMemory: true}); err == nil {
+			t.Fatalf("%s: expected a type error", name)
+		}
+	}
+}
+
+func TestCompilePM256(t *testing.T) {
+	input, err := os.ReadFile("../example/hashing.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Registry"]
+
+	// Reference encoding of pm256("ryot", 1, true), built by hand.
+	data := []byte{0, 0, 0, 0, 0, 0, 0, 4, 'r', 'y', 'o', 't'}
+	data = append(data, 0, 0, 0, 0, 0, 0, 0, 1)
+	data = append(data, 1)
+	sum := pm256.Sum256(data)
+	expected := "0x" + hex.EncodeToString(sum[:])
+
+	var folded []string
+	runtimeHashes := 0
+	for _, instr := range contract.Bytecode {
+		if instr.Opcode != codegen.OpHash {
+			continue
+		}
+		switch arg := instr.Args[0].(type) {
+		case string:
+			folded = append(folded, arg)
+		case uint64:
+			if arg != 3 {
+				t.Fatalf("expected HASH over 3 arguments, got %d", arg)
+			}
+			runtimeHashes++
+		}
+	}
+
+	if runtimeHashes != 1 {
+		t.Fatalf("expected 1 runtime HASH, got %d", runtimeHashes)
+	}
+	if len(folded) != 2 || folded[0] != expected {
+		t.Fatalf("expected folded pm256 %s, got %v", expected, folded)
+	}
+
+	// Nested calls fold through the inner hash.
+	inner := pm256.Sum256([]byte{0, 0, 0, 0, 0, 0, 0, 1, 'a'})
+	addr, _ := hex.DecodeString("dc6e0e801fbe5ae5f2799361d34b53")
+	outer := pm256.Sum256(append(inner[:], addr...))
+	if folded[1] != "0x"+hex.EncodeToString(outer[:]) {
+		t.Fatalf("nested pm256 folded to %s", folded[1])
+	}
+}
+
+func TestCompilePM256Arguments(t *testing.T) {
+	tests := map[string]struct{ fn, message string }{
+		"empty": {"pub func f(): hash { return pm256(); }", "'pm256' espera al menos un argumento"},
+		"array": {"pub func f(): hash { return pm256([1, 2]); }", "el argumento 1 de 'pm256' no es codificable"},
+	}
+
+	for name, tt := range tests {
+		input := `pragma: "1.0.0";
+		class contract Registry {
+			` + tt.fn + `
+		}
+		`
+		_, err := CompileWithOptions(input, Options{InMemory: true})
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Fatalf("%s: expected %q, got %v", name, tt.message, err)
+		}
+	}
+}
+
+func TestCompileInterfaces(t *testing.T) {
+	input, err := os.ReadFile("../example/interfaces.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Wallet"]
+
+	token, ok := contracts["IToken"]
+	if !ok || !token.IsInterface || len(token.ABI) != 2 {
+		t.Fatalf("expected the IToken ABI with 2 functions, got %+v", token)
+	}
+	if len(token.Bytecode) != 0 || len(token.InitBytecode) != 0 {
+		t.Fatalf("interfaces must not produce bytecode")
+	}
+	iface := token.ABI
+	transfer := codegen.NewSelector("transfer", []string{"address", "uint64"})
+	if iface[0].Selector != transfer.String() {
+		t.Fatalf("expected transfer selector %s, got %s", transfer, iface[0].Selector)
+	}
+
+	calls := 0
+	for _, instr := range contract.Bytecode {
+		if instr.Opcode == codegen.OpContract && instr.Args[0] == "IToken" {
+			t.Fatalf("interfaces must not produce bytecode")
+		}
+		if instr.Opcode == codegen.OpExtCall {
+			calls++
+			if calls == 1 && (instr.Args[0] != transfer || instr.Args[2] != "bool") {
+				t.Fatalf("unexpected external call %s", instr.Raw)
+			}
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 external calls, got %d", calls)
+	}
+	for _, entry := range contract.ABI {
+		if entry.Name == "transfer" {
+			t.Fatalf("interface functions must not be part of the contract ABI")
+		}
+	}
+}
+
+func TestCompileInterfaceCallErrors(t *testing.T) {
+	tests := map[string]struct{ fn, message string }{
+		"unknown interface": {"pub func f(a: address): bool { return IMissing(a).transfer(a, 1); }", "interfaz no declarada 'IMissing'"},
+		"unknown function":  {"pub func f(a: address): bool { return IToken(a).approve(a, 1); }", "la interfaz 'IToken' no declara la función 'approve'"},
+		"arity":             {"pub func f(a: address): bool { return IToken(a).transfer(a); }", "'IToken.transfer' espera 2 argumentos, recibió 1"},
+		"argument type":     {"pub func f(a: address): bool { return IToken(a).transfer(1, 1); }", "el argumento 1 de 'IToken.transfer' debe ser address, recibió uint64"},
+		"address type":      {"pub func f(a: address): bool { return IToken(5).transfer(a, 1); }", "'IToken(...)' espera una dirección, recibió uint64"},
+	}
+
+	for name, tt := range tests {
+		input := `pragma: "1.0.0";
+		class interface IToken {
+			pub func transfer(to: address, amount: uint64): bool;
+		}
+		class contract Wallet {
+			` + tt.fn + `
+		}
+		`
+		_, err := CompileWithOptions(input, Options{InMemory: true})
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Fatalf("%s: expected %q, got %v", name, tt.message, err)
+		}
+	}
+}
+
+func TestCompileInheritance(t *testing.T) {
+	input, err := os.ReadFile("../example/inheritance.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Token"]
+
+	// Token hereda el almacenamiento y las funciones de Ownable y Pausable.
+	inToken := false
+	found := map[string]bool{}
+	for _, instr := range contract.Bytecode {
+		if instr.Opcode == codegen.OpContract {
+			inToken = instr.Args[0] == "Token"
+		}
+		if inToken && (instr.Opcode == codegen.OpFunc || instr.Opcode == codegen.OpStore) {
+			found[instr.Args[0].(string)] = true
+		}
+	}
+	for _, name := range []string{"owners", "paused", "balances", "isOwner", "pause", "transfer", "balance"} {
+		if !found[name] {
+			t.Fatalf("expected Token to contain '%s', got %v", name, found)
+		}
+	}
+
+	// El constructor heredado de Ownable se ejecuta al desplegar Token.
+	inToken = false
+	constructors := 0
+	for _, instr := range contract.InitBytecode {
+		if instr.Opcode == codegen.OpContract {
+			inToken = instr.Args[0] == "Token"
+		}
+		if inToken && instr.Opcode == codegen.OpConstructor {
+			constructors++
+		}
+	}
+	if constructors != 1 {
+		t.Fatalf("expected Token to run the inherited constructor, got %d constructors", constructors)
+	}
+}
+
+func TestCompileInheritanceErrors(t *testing.T) {
+	tests := map[string]struct{ body, message, position string }{
+		"missing override": {`class contract Base { pub func f(): uint64 { return 1; } }
+			class contract Child is Base { pub func f(): uint64 { return 2; } }`,
+			"la función 'f' de 'Child' redefine la de 'Base' sin 'override'", "3:39"},
+		"override without base": {`class contract Base { pub func f(): uint64 { return 1; } }
+			class contract Child is Base { pub func g() override: uint64 { return 2; } }`,
+			"la función 'g' de 'Child' usa 'override' pero no redefine ninguna función heredada", "3:39"},
+		"signature mismatch": {`class contract Base { pub func f(): uint64 { return 1; } }
+			class contract Child is Base { pub func f() override: bool { return true; } }`,
+			"la función 'f' de 'Child' no tiene la misma firma que la de 'Base'", "3:39"},
+		"unknown parent": {`class contract Child is Missing { pub func f(): uint64 { return 1; } }`,
+			"clase base no declarada 'Missing'", "2:3"},
+		"cycle": {`class contract A is B { pub func f(): uint64 { return 1; } }
+			class contract B is A { pub func g(): uint64 { return 1; } }`,
+			"herencia circular en 'A'", "2:3"},
+		"duplicate storage": {`class contract Base { pub storage s(k: uint64): uint64; }
+			class contract Child is Base { pub storage s(k: uint64): uint64; }`,
+			"'s' se declara en 'Base' y en 'Child'", "3:39"},
+		"missing implementation": {`class interface IToken { pub func transfer(to: address, amount: uint64): bool; }
+			class contract Token is IToken { pub func f(): uint64 { return 1; } }`,
+			"el contrato 'Token' no implementa 'IToken.transfer'", "3:4"},
+		"interface signature": {`class interface IToken { pub func transfer(to: address, amount: uint64): bool; }
+			class contract Token is IToken { pub func transfer(to: address): bool { return true; } }`,
+			"'Token.transfer' no tiene la firma de 'IToken.transfer'", "3:41"},
+	}
+
+	for name, tt := range tests {
+		input := `pragma: "1.0.0";
+		` + tt.body + `
+		`
+		_, err := CompileWithOptions(input, Options{InMemory: true})
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Fatalf("%s: expected %q, got %v", name, tt.message, err)
+		}
+		if !strings.HasPrefix(err.Error(), "<input>:"+tt.position+": ") {
+			t.Fatalf("%s: expected the error at %s, got %v", name, tt.position, err)
+		}
+	}
+}
+
+func TestCompileCreate(t *testing.T) {
+	input, err := os.ReadFile("../example/factory.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Factory"]
+
+	// El código de Factory incrusta el de Vault: inicialización y runtime.
+	current := ""
+	embedded := []string{}
+	created, destroyed := false, false
+	for _, instr := range contract.Bytecode {
+		switch instr.Opcode {
+		case codegen.OpContract:
+			if current == "Factory" {
+				embedded = append(embedded, instr.Args[0].(string))
+			} else {
+				current = instr.Args[0].(string)
+			}
+		case codegen.OpEmbed:
+			if current != "Factory" || instr.Args[0] != "Vault" {
+				t.Fatalf("unexpected embed %s in %s", instr.Raw, current)
+			}
+		case codegen.OpCreate:
+			created = current == "Factory" && instr.Args[0] == "Vault" && instr.Args[1] == uint64(1)
+		case codegen.OpSelfDestruct:
+			destroyed = true
+		case codegen.OpEnd:
+			if instr.Args[0] == "EMBED" {
+				current = ""
+			}
+		}
+	}
+	if !created {
+		t.Fatalf("expected Factory to create Vault with 1 argument")
+	}
+	if !destroyed {
+		t.Fatalf("expected a SELFDESTRUCT instruction")
+	}
+	if len(embedded) != 2 || embedded[0] != "Vault" || embedded[1] != "Vault" {
+		t.Fatalf("expected the Vault init and runtime code embedded in Factory, got %v", embedded)
+	}
+}
+
+func TestCompileCreateErrors(t *testing.T) {
+	tests := map[string]string{
+		"self": `class contract A { pub func f(): address { return create A(); } }`,
+		"cycle": `class contract A { pub func f(): address { return create B(); } }
+			class contract B { pub func f(): address { return create A(); } }`,
+		"unknown":   `class contract A { pub func f(): address { return create Missing(); } }`,
+		"interface": `class interface I { pub func g(): bool; } class contract A { pub func f(): address { return create I(); } }`,
+		"arity": `class contract C { constructor(owner: address) {} }
+			class contract A { pub func f(): address { return create C(); } }`,
+		"argument type": `class contract C { constructor(owner: address) {} }
+			class contract A { pub func f(): address { return create C(1); } }`,
+		"selfdestruct": `class contract A { pub func f(): void { selfdestruct(1); } }`,
+	}
+
+	for name, body := range tests {
+		input := `pragma: "1.0.0";
+		` + body + `
+		`
+		if _, err := CompileWithOptions(input, Options{InMemory: true}); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCompileMultipleContracts(t *testing.T) {
+	input, err := os.ReadFile("../example/factory.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) != 2 {
+		t.Fatalf("expected 2 compiled contracts, got %d", len(contracts))
+	}
+
+	for name, contract := range contracts {
+		if contract.Name != name {
+			t.Fatalf("contract %s compiled with name %s", name, contract.Name)
+		}
+		if contract.Bytecode[0].Opcode != codegen.OpContract || contract.Bytecode[0].Args[0] != name {
+			t.Fatalf("expected the runtime code of %s to start with its own CONTRACT, got %s", name, contract.Bytecode[0].Raw)
+		}
+		if contract.InitBytecode[0].Args[0] != name {
+			t.Fatalf("expected the init code of %s to start with its own CONTRACT, got %s", name, contract.InitBytecode[0].Raw)
+		}
+		for _, file := range []string{"abi.json", "bytecode.ryc", "bytecode.rybc", "init.ryc", "init.rybc"} {
+			if len(contract.Artifacts[file]) == 0 {
+				t.Fatalf("expected artifact %s for %s", file, name)
+			}
+		}
+	}
+
+	// Cada contrato tiene su propia ABI.
+	for _, entry := range contracts["Factory"].ABI {
+		if entry.Name == "close" || entry.Type == "constructor" {
+			t.Fatalf("unexpected Vault entry in the Factory ABI: %+v", entry)
+		}
+	}
+	if contracts["Vault"].ABI[0].Type != "constructor" {
+		t.Fatalf("expected the Vault ABI to start with its constructor, got %+v", contracts["Vault"].ABI[0])
+	}
+}
+
+func TestCompileImports(t *testing.T) {
+	contracts, err := CompileFileWithOptions("../example/imports/token.ry", Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"Token", "Ownable", "IToken"} {
+		if _, ok := contracts[name]; !ok {
+			t.Fatalf("expected %s in the compilation unit, got %v", name, contracts)
+		}
+	}
+	if _, ok := contracts["Wallet"]; ok {
+		t.Fatalf("Wallet was not imported")
+	}
+	constructor := false
+	for _, instr := range contracts["Token"].InitBytecode {
+		constructor = constructor || instr.Opcode == codegen.OpConstructor
+	}
+	if !constructor {
+		t.Fatalf("expected Token to inherit the constructor of the imported Ownable")
+	}
+}
+
+// writeModules escribe los archivos indicados en un directorio temporal y devuelve su ruta.
+func writeModules(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, body := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(`pragma: "1.0.0";`+"\n"+body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCompileImportSearchPath(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib/interfaces.ry": `class interface IToken { pub func balance(account: address): uint64; }`,
+		"main.ry": `import { IToken } from "interfaces.ry";
+			class contract Wallet { pub func holdings(token: address): uint64 { return IToken(token).balance(self()); } }`,
+	})
+
+	if _, err := CompileFileWithOptions(filepath.Join(dir, "main.ry"), Options{InMemory: true}); err == nil {
+		t.Fatalf("expected an error without a search path")
+	}
+	contracts, err := CompileFileWithOptions(filepath.Join(dir, "main.ry"), Options{InMemory: true, SearchPath: []string{filepath.Join(dir, "lib")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := contracts["IToken"]; !ok {
+		t.Fatalf("expected IToken to be imported from the search path")
+	}
+}
+
+func TestCompileNamedImportDependencies(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib.ry": `class interface IToken { pub func balance(account: address): uint64; }
+			class contract Vault { }
+			class contract Reader {
+				pub func read(token: address): uint64 { return IToken(token).balance(self()); }
+				pub func spawn(): address { return create Vault(); }
+			}`,
+		"main.ry": `import { Reader } from "./lib.ry";
+			class contract Main is Reader { }`,
+	})
+
+	contracts, err := CompileFileWithOptions(filepath.Join(dir, "main.ry"), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Reader", "IToken", "Vault", "Main"} {
+		if _, ok := contracts[name]; !ok {
+			t.Fatalf("expected %s in the compilation unit, got %v", name, contracts)
+		}
+	}
+}
+
+func TestCompileImportErrors(t *testing.T) {
+	tests := map[string]map[string]string{
+		"cycle": {
+			"main.ry": `import "./a.ry"; class contract Main { }`,
+			"a.ry":    `import "./b.ry"; class contract A { }`,
+			"b.ry":    `import "./a.ry"; class contract B { }`,
+		},
+		"collision with local": {
+			"main.ry": `import "./a.ry"; class contract A { }`,
+			"a.ry":    `class contract A { }`,
+		},
+		"collision between imports": {
+			"main.ry": `import "./a.ry"; import "./b.ry"; class contract Main { }`,
+			"a.ry":    `class contract Shared { }`,
+			"b.ry":    `class contract Shared { }`,
+		},
+		"collision with hidden parent": {
+			"main.ry": `import { Token } from "./base.ry"; class contract Ownable { }`,
+			"base.ry": `class contract Ownable { pub func owned(): bool { return true; } }
+				class contract Token is Ownable { }`,
+		},
+		"missing name": {
+			"main.ry": `import { Missing } from "./a.ry"; class contract Main { }`,
+			"a.ry":    `class contract A { }`,
+		},
+		"missing file": {
+			"main.ry": `import "./missing.ry"; class contract Main { }`,
+		},
+	}
+
+	for name, files := range tests {
+		dir := writeModules(t, files)
+		if _, err := CompileFileWithOptions(filepath.Join(dir, "main.ry"), Options{InMemory: true}); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCompileWithOptionsOutputDir(t *testing.T) {
+	input, err := os.ReadFile("../example/factory.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	unrelated := filepath.Join(dir, "keep.txt")
+	if err := os.WriteFile(unrelated, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{OutputDir: dir, Artifacts: ArtifactABI | ArtifactRYBC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Fatalf("the output directory must not be wiped: %v", err)
+	}
+	for _, name := range []string{"Factory", "Vault"} {
+		if len(contracts[name].Artifacts) != 2 {
+			t.Fatalf("expected 2 artifacts for %s, got %d", name, len(contracts[name].Artifacts))
+		}
+		for file, data := range contracts[name].Artifacts {
+			written, err := os.ReadFile(filepath.Join(dir, name, file))
+			if err != nil || string(written) != string(data) {
+				t.Fatalf("expected %s/%s to be written: %v", name, file, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, name, "bytecode.ryc")); err == nil {
+			t.Fatalf("bytecode.ryc was not selected")
+		}
+	}
+}
+
+func TestCompileWithOptionsInMemory(t *testing.T) {
+	fsys := NewMemoryFS(map[string]string{
+		"contracts/interfaces.ry": `pragma: "1.0.0";
+			class interface IToken { pub func balance(account: address): uint64; }`,
+	})
+	src := `pragma: "1.0.0";
+	import { IToken } from "./interfaces.ry";
+	class contract Wallet { pub func holdings(token: address): uint64 { return IToken(token).balance(self()); } }
+	`
+
+	dir := filepath.Join(t.TempDir(), "out")
+	contracts, err := CompileWithOptions(src, Options{
+		OutputDir: dir,
+		InMemory:  true,
+		FS:        fsys,
+		Filename:  "contracts/main.ry",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := contracts["IToken"]; !ok {
+		t.Fatalf("expected IToken to be imported from the in-memory filesystem")
+	}
+	if len(contracts["Wallet"].Artifacts) != 7 || len(contracts["Wallet"].Artifacts["bytecode.rybc"]) == 0 {
+		t.Fatalf("expected all Wallet artifacts in memory, got %d", len(contracts["Wallet"].Artifacts))
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Fatalf("in-memory mode must not write to disk")
+	}
+	if _, err := fsys.ReadFile(filepath.Join(dir, "Wallet", "abi.json")); err == nil {
+		t.Fatalf("in-memory mode must not write to the filesystem")
+	}
+
+	// Sin el modo en memoria los artefactos se escriben en el FileSystem indicado.
+	if _, err := CompileWithOptions(src, Options{OutputDir: "out", FS: fsys, Filename: "contracts/main.ry"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsys.ReadFile("out/Wallet/bytecode.rybc"); err != nil {
+		t.Fatalf("expected the artifacts in the filesystem: %v", err)
+	}
+	if _, err := os.Stat("out"); err == nil {
+		t.Fatalf("artifacts must not be written to disk")
+	}
+}
+
+func TestCompileFileWithOptionsFS(t *testing.T) {
+	fsys := NewMemoryFS(map[string]string{
+		"contracts/interfaces.ry": `pragma: "1.0.0";
+			class interface IToken { pub func balance(account: address): uint64; }`,
+		"contracts/main.ry": `pragma: "1.0.0";
+			import { IToken } from "./interfaces.ry";
+			class contract Wallet { pub func holdings(token: address): uint64 { return IToken(token).balance(self()); } }`,
+	})
+
+	contracts, err := CompileFileWithOptions("contracts/main.ry", Options{InMemory: true, FS: fsys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := contracts["Wallet"]; !ok {
+		t.Fatalf("expected Wallet to be read from the in-memory filesystem")
+	}
+	if _, err := CompileFileWithOptions("contracts/missing.ry", Options{InMemory: true, FS: fsys}); err == nil {
+		t.Fatalf("expected an error for a file missing from the filesystem")
+	}
+}
+
+func TestCompileInterfaceErrorFile(t *testing.T) {
+	fsys := NewMemoryFS(map[string]string{
+		"contracts/bad.ry": `pragma: "1.0.0";
+			class interface IBad { pub func g(): uint64 { return 1; } }`,
+		"contracts/main.ry": `pragma: "1.0.0";
+			import "./bad.ry";
+			class contract Main { pub func f(): uint64 { return 1; } }`,
+	})
+
+	// The error is reported in the interface's file, not in the one of the
+	// contract handled before it.
+	_, err := CompileFileWithOptions("contracts/main.ry", Options{InMemory: true, FS: fsys})
+	var diag Diagnostic
+	if !errors.As(err, &diag) || diag.File != "contracts/bad.ry" || !strings.Contains(diag.Message, "interfaz 'IBad'") {
+		t.Fatalf("expected the interface error in contracts/bad.ry, got %v", err)
+	}
+}
+
+// recordingLogger guarda los mensajes recibidos.
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestCompileWithOptionsLogger(t *testing.T) {
+	input, err := os.ReadFile("../example/constructor.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &recordingLogger{}
+	if _, err := CompileWithOptions(string(input), Options{InMemory: true, Logger: logger}); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range logger.lines {
+		if strings.HasPrefix(line, "Parsing") {
+			t.Fatalf("the parse trace must be opt-in, got %q", line)
+		}
+	}
+	if len(logger.lines) == 0 {
+		t.Fatalf("expected compiler messages in the logger")
+	}
+
+	traced := &recordingLogger{}
+	if _, err := CompileWithOptions(string(input), Options{InMemory: true, Logger: traced, Trace: true}); err != nil {
+		t.Fatal(err)
+	}
+	parsing := 0
+	for _, line := range traced.lines {
+		if strings.HasPrefix(line, "Parsing") {
+			parsing++
+		}
+	}
+	if parsing == 0 {
+		t.Fatalf("expected the parse trace in the logger, got %v", traced.lines)
+	}
+}
+
+func TestCompileStandardJSON(t *testing.T) {
+	input := StandardInput{
+		Language: Language,
+		Sources: map[string]StandardSource{
+			"lib/ownable.ry": {Content: `pragma: "1.0.0";
+class contract Ownable {
+    pub storage owners(account: address): bool;
+    pub func isOwner(account: address): bool { return owners(account); }
+}
+`},
+			"token.ry": {Content: `pragma: "1.0.0";
+import "./lib/ownable.ry";
+class contract Token is Ownable {
+    pub storage balances(account: address): uint64;
+    uint64 supply: 0;
+    pub func total(): uint64 { return supply; }
+}
+`},
+		},
+		Settings: StandardSettings{
+			OutputSelection: map[string][]string{
+				"*":        {OutputABI},
+				"token.ry": {OutputBytecode, OutputStorageLayout},
+			},
+		},
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := CompileStandardJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out StandardOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", out.Errors)
+	}
+	if out.Version != Version {
+		t.Fatalf("expected version %s, got %s", Version, out.Version)
+	}
+
+	if _, ok := out.Contracts["token.ry"]["Ownable"]; ok {
+		t.Fatalf("imported contracts must only be reported by the file that declares them")
+	}
+	ownable := out.Contracts["lib/ownable.ry"]["Ownable"]
+	if len(ownable.ABI) == 0 || ownable.Bytecode != "" || ownable.StorageLayout != nil {
+		t.Fatalf("expected only the ABI of Ownable, got %+v", ownable)
+	}
+
+	token := out.Contracts["token.ry"]["Token"]
+	if len(token.ABI) == 0 || token.RYC != "" {
+		t.Fatalf("expected the ABI without RYC for Token, got %+v", token)
+	}
+	bytecode, err := hex.DecodeString(token.Bytecode)
+	if err != nil || !strings.HasPrefix(string(bytecode), "RYBC") {
+		t.Fatalf("expected hex encoded RYBC bytecode, got %q", token.Bytecode)
+	}
+	// La clase heredada aporta su almacenamiento antes que el de Token.
+	want := []codegen.StorageEntry{
+		{Name: "owners", Keys: []string{"address"}, Type: "bool"},
+		{Name: "balances", Keys: []string{"address"}, Type: "uint64"},
+		{Name: "supply", Type: "uint64"},
+	}
+	if fmt.Sprint(token.StorageLayout) != fmt.Sprint(want) {
+		t.Fatalf("expected storage layout %v, got %v", want, token.StorageLayout)
+	}
+}
+
+func TestCompileStandardJSONDiagnostics(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  Diagnostic
+	}{
+		"invalid json": {
+			input: `{"sources":`,
+			want:  Diagnostic{Severity: SeverityError},
+		},
+		"unknown language": {
+			input: `{"language":"Solidity","sources":{"a.ry":{"content":""}}}`,
+			want:  Diagnostic{Severity: SeverityError},
+		},
+		"syntax error": {
+			input: `{"sources":{"a.ry":{"content":"pragma: \"1.0.0\";\nclass contract A {\n  pub func f(x uint64): uint64 { return x; }\n}"}}}`,
+			want:  Diagnostic{Severity: SeverityError, File: "a.ry", Line: 3, Column: 16},
+		},
+		"codegen error": {
+			input: `{"sources":{"a.ry":{"content":"pragma: \"1.0.0\";\nclass contract A {\n  pub func f(): address {\n    return create Missing();\n  }\n}"}}}`,
+			want:  Diagnostic{Severity: SeverityError, File: "a.ry", Line: 4, Column: 12, Message: "codegen: contrato no declarado 'Missing'"},
+		},
+		"error in import": {
+			input: `{"sources":{"a.ry":{"content":"pragma: \"1.0.0\";\nimport \"./b.ry\";\nclass contract A {}"},"b.ry":{"content":"pragma: \"1.0.0\";\nclass contract B { pub func f(: uint64 {} }"}}}`,
+			want:  Diagnostic{Severity: SeverityError, File: "b.ry", Line: 2},
+		},
+	}
+
+	for name, tt := range tests {
+		raw, err := CompileStandardJSON([]byte(tt.input))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var out StandardOutput
+		if err := json.Unmarshal(raw, &out); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(out.Errors) == 0 {
+			t.Fatalf("%s: expected errors", name)
+		}
+		got := out.Errors[0]
+		if got.Severity != tt.want.Severity || got.File != tt.want.File || got.Line != tt.want.Line ||
+			(tt.want.Column != 0 && got.Column != tt.want.Column) || (tt.want.Message != "" && got.Message != tt.want.Message) {
+			t.Fatalf("%s: expected %+v, got %+v", name, tt.want, got)
+		}
+		if got.Message == "" {
+			t.Fatalf("%s: expected a message", name)
+		}
+	}
+}
+
+func TestCompilePragmaRanges(t *testing.T) {
+	body := "\nclass contract A { pub func f(): uint64 { return 1; } }\n"
+	for _, pragma := range []string{"1.0.0", "=1.0.0", "^1.0.0", "~1.0.0", ">=1.0.0 <2.0.0", ">0.9.0", "^2.0.0 || ^1.0.0"} {
+		if _, err := CompileWithOptions(`pragma: "`+pragma+`";`+body, Options{InMemory: true}); err != nil {
+			t.Fatalf("%s: %v", pragma, err)
+		}
+	}
+
+	tests := map[string]string{
+		"^2.0.0":        `use un compilador compatible con el rango (p. ej. 2.0.0) o cambie el pragma a "^1.0.0"`,
+		"<1.0.0":        `(p. ej. 0.0.0)`,
+		"~1.1.0":        `(p. ej. 1.1.0)`,
+		">2.0.0 <1.0.0": `ninguna versión cumple el rango`,
+		"latest":        `pragma inválido "latest"`,
+	}
+	for pragma, want := range tests {
+		_, err := CompileWithOptions(`pragma: "`+pragma+`";`+body, Options{InMemory: true})
+		if err == nil {
+			t.Fatalf("%s: expected an error", pragma)
+		}
+		var diag Diagnostic
+		if !errors.As(err, &diag) || diag.Line != 1 || diag.Column != 1 {
+			t.Fatalf("%s: expected a diagnostic at the pragma, got %v", pragma, err)
+		}
+		if !strings.Contains(diag.Message, want) {
+			t.Fatalf("%s: expected %q in %q", pragma, want, diag.Message)
+		}
+	}
+
+	// Los módulos importados pueden declarar su propio rango, pero debe admitir este compilador.
+	fsys := NewMemoryFS(map[string]string{
+		"lib/a.ry":   `pragma: ">=1.0.0 <2.0.0"; class contract A { }`,
+		"lib/old.ry": `pragma: "0.9.0"; class contract Old { }`,
+	})
+	opts := Options{InMemory: true, FS: fsys, Filename: "main.ry"}
+	if _, err := CompileWithOptions(`pragma: "^1.0.0"; import "./lib/a.ry"; class contract Main is A { }`, opts); err != nil {
+		t.Fatal(err)
+	}
+	_, err := CompileWithOptions(`pragma: "^1.0.0"; import "./lib/old.ry"; class contract Main { }`, opts)
+	var diag Diagnostic
+	if !errors.As(err, &diag) || diag.File != "lib/old.ry" {
+		t.Fatalf("expected a diagnostic in lib/old.ry, got %v", err)
+	}
+}
+
+func TestCompileDocs(t *testing.T) {
+	fsys := NewMemoryFS(map[string]string{
+		"lib/owned.ry": `pragma: "1.0.0";
+/// Cuenta con un propietario.
+class contract Owned {
+
+    /// Propietario actual.
+    pub func owner(): address { return self(); }
+}`,
+		"bad.ry": `pragma: "1.0.0";
+class contract Bad {
+
+    /// @param who Nadie.
+    pub func f(): void {}
+}`,
+	})
+	src := `pragma: "1.0.0";
+import "./lib/owned.ry";
+
+/// Una bóveda.
+class contract Vault is Owned {
+
+    /// Deposita fondos.
+    /// @param amount Cantidad depositada.
+    pub func deposit(amount: uint64): void {}
+}
+`
+	contracts, err := CompileWithOptions(src, Options{InMemory: true, FS: fsys, Filename: "vault.ry", Artifacts: AllArtifacts | ArtifactMarkdown})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts := contracts["Vault"].Artifacts
+	var userdoc struct {
+		Notice  string                       `json:"notice"`
+		Methods map[string]map[string]string `json:"methods"`
+	}
+	if err := json.Unmarshal(artifacts["userdoc.json"], &userdoc); err != nil {
+		t.Fatal(err)
+	}
+	if userdoc.Notice != "Una bóveda." || userdoc.Methods["owner()"]["notice"] != "Propietario actual." || userdoc.Methods["deposit(uint64)"]["notice"] != "Deposita fondos." {
+		t.Fatalf("unexpected userdoc.json: %s", artifacts["userdoc.json"])
+	}
+	if !strings.Contains(string(artifacts["devdoc.json"]), `"amount": "Cantidad depositada."`) {
+		t.Fatalf("unexpected devdoc.json: %s", artifacts["devdoc.json"])
+	}
+	if !strings.HasPrefix(string(artifacts["doc.md"]), "# Vault\n\nUna bóveda.\n") {
+		t.Fatalf("unexpected doc.md:\n%s", artifacts["doc.md"])
+	}
+	if contracts["Owned"].Doc == nil || contracts["Owned"].Doc.Notice != "Cuenta con un propietario." {
+		t.Fatalf("expected the imported class to keep its documentation")
+	}
+
+	out := CompileStandard(StandardInput{
+		Sources:  map[string]StandardSource{"vault.ry": {Content: src}, "lib/owned.ry": {Content: string(fsys.files["lib/owned.ry"])}},
+		Settings: StandardSettings{OutputSelection: map[string][]string{"*": {OutputDevDoc}}},
+	})
+	vault := out.Contracts["vault.ry"]["Vault"]
+	if len(out.Errors) > 0 || vault.DevDoc == nil || vault.UserDoc != nil || vault.DevDoc.Methods["deposit(uint64)"].Params["amount"] != "Cantidad depositada." {
+		t.Fatalf("unexpected standard JSON output: %+v", out)
+	}
+
+	_, err = CompileWithOptions(`pragma: "1.0.0";
+import "./bad.ry";
+class contract Main is Bad {}`, Options{InMemory: true, FS: fsys, Filename: "main.ry"})
+	diags := AsDiagnostics(err)
+	if len(diags) != 1 || diags[0].Error() != "bad.ry:4:9: doc: '@param who' no corresponde a ningún parámetro" {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestCompileControlFlow(t *testing.T) {
+	_, err := CompileWithOptions(`pragma: "1.0.0";
+class contract A {
+    pub func f(a: uint64): uint64 {
+        check(a > 0, err: "zero");
+    }
+    pub func g(): uint64 {
+        uint64 x: 1;
+    }
+}`, Options{InMemory: true, Filename: "a.ry"})
+	diags := AsDiagnostics(err)
+	if len(diags) != 2 || diags[0].Error() != "a.ry:3:9: flow: la función 'f' devuelve uint64 pero puede terminar sin return" ||
+		diags[1].Line != 6 || diags[1].Severity != SeverityError {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	fsys := NewMemoryFS(map[string]string{
+		"lib.ry": `pragma: "1.0.0";
+class contract Base {
+    pub func base(): uint64 {
+        return 1;
+        return 2;
+    }
+}`,
+	})
+	src := `pragma: "1.0.0";
+import "./lib.ry";
+class contract A is Base {
+    pub func f(a: uint64): uint64 {
+        check(false, err: "disabled");
+        return a;
+    }
+    pub func g(): void {
+        revert Missing();
+        uint64 x: 1;
+    }
+    error Missing();
+}`
+	contracts, err := CompileWithOptions(src, Options{InMemory: true, FS: fsys, Filename: "a.ry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := Warnings(contracts)
+	if len(warnings) != 2 || warnings[0].Error() != "a.ry:6:9: flow: código inalcanzable después de un check que siempre falla" ||
+		warnings[1].Line != 10 || warnings[1].Severity != SeverityWarning {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	if len(contracts["Base"].Warnings) != 1 || contracts["Base"].Warnings[0].File != "lib.ry" {
+		t.Fatalf("expected the imported class to keep its own warning, got %v", contracts["Base"].Warnings)
+	}
+
+	out := CompileStandard(StandardInput{Sources: map[string]StandardSource{"a.ry": {Content: src}, "lib.ry": {Content: string(fsys.files["lib.ry"])}}})
+	if len(out.Errors) != 3 || out.Errors[0].Severity != SeverityWarning || out.Contracts["a.ry"]["A"].ABI == nil {
+		t.Fatalf("expected the warnings of both files next to the contracts, got %+v", out.Errors)
+	}
+}
+
+func TestCompileStateMutability(t *testing.T) {
+	contracts, err := CompileWithOptions(`pragma: "1.0.0";
+class interface IOracle {
+    pub func price() view: uint64;
+    pub func update(p: uint64): void;
+}
+class contract A {
+    pub storage balances(account: address): uint64;
+    uint64 fee: 2;
+    constructor(initial: uint64) {
+        balances(caller()): initial;
+    }
+    modifier onlyPositive(a: uint64) {
+        check(a > 0, err: "zero");
+        _;
+    }
+    pub func double(a: uint64) pure: uint64 {
+        return a + a;
+    }
+    pub func guarded(a: uint64) onlyPositive(a): uint64 {
+        return a;
+    }
+    pub func balance(account: address): uint64 {
+        return balances(account);
+    }
+    pub func withFee(a: uint64): uint64 {
+        return a + fee;
+    }
+    pub func mine(): uint64 {
+        return balance(caller());
+    }
+    pub func quote(oracle: address): uint64 {
+        return IOracle(oracle).price();
+    }
+    pub func deposit(amount: uint64): void {
+        balances(caller()): balances(caller()) + amount;
+    }
+    pub func depositTwice(amount: uint64): void {
+        deposit(amount);
+        deposit(amount);
+    }
+}`, Options{InMemory: true, Filename: "a.ry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, entry := range contracts["A"].ABI {
+		got[entry.Type+" "+entry.Name] = entry.StateMut
+	}
+	want := map[string]string{
+		"constructor ":          codegen.MutabilityNonPayable,
+		"function double":       codegen.MutabilityPure,
+		"function guarded":      codegen.MutabilityPure,
+		"function balance":      codegen.MutabilityView,
+		"function withFee":      codegen.MutabilityView,
+		"function mine":         codegen.MutabilityView,
+		"function quote":        codegen.MutabilityView,
+		"function deposit":      codegen.MutabilityNonPayable,
+		"function depositTwice": codegen.MutabilityNonPayable,
+	}
+	for name, mutability := range want {
+		if got[name] != mutability {
+			t.Errorf("%s: expected %q, got %q", name, mutability, got[name])
+		}
+	}
+	oracle := contracts["IOracle"].ABI
+	if oracle[0].StateMut != codegen.MutabilityView || oracle[1].StateMut != codegen.MutabilityNonPayable {
+		t.Fatalf("unexpected interface mutability: %+v", oracle)
+	}
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`pub func f(a: uint64) pure: uint64 {
+        uint64 b: a;
+        return b + fee;
+    }`, "a.ry:7:9: codegen: la función 'f' se declaró pure pero lee la variable 'fee'"},
+		{`pub func f(a: uint64) view: void {
+        check(a > 0, err: "zero");
+        balances(caller()): a;
+    }`, "a.ry:7:9: codegen: la función 'f' se declaró view pero escribe en el storage 'balances'"},
+		{`pub func set(a: uint64): void {
+        balances(caller()): a;
+    }
+    pub func f(a: uint64) view: void {
+        set(a);
+    }`, "a.ry:9:9: codegen: la función 'f' se declaró view pero llama a 'set', que es nonpayable"},
+	}
+	for _, tt := range tests {
+		_, err := CompileWithOptions(`pragma: "1.0.0";
+class contract A {
+    pub storage balances(account: address): uint64;
+    uint64 fee: 2;
+    `+tt.src+`
+}`, Options{InMemory: true, Filename: "a.ry"})
+		if diags := AsDiagnostics(err); len(diags) != 1 || diags[0].Error() != tt.want {
+			t.Errorf("unexpected diagnostics: %v\nwant %s", diags, tt.want)
+		}
+	}
+}
+
+func TestCompilePayable(t *testing.T) {
+	input, err := os.ReadFile("../example/payable.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Vault"]
+
+	mutability := make(map[string]string)
+	for _, entry := range contract.ABI {
+		mutability[entry.Name] = entry.StateMut
+	}
+	if mutability["deposit"] != codegen.MutabilityPayable || mutability["withdraw"] != codegen.MutabilityNonPayable || mutability["deposited"] != codegen.MutabilityView {
+		t.Fatalf("unexpected state mutability: %v", mutability)
+	}
+
+	// Only the non-payable functions reject value, and only in their external entry.
+	guarded := make(map[string]bool)
+	seen := map[codegen.Opcode]bool{}
+	entry := ""
+	for _, instr := range contract.Bytecode {
+		seen[instr.Opcode] = true
+		switch {
+		case instr.Opcode == codegen.OpEntry:
+			entry = instr.Args[0].(string)
+		case instr.Opcode == codegen.OpEnd && instr.Args[0] == "ENTRY":
+			entry = ""
+		case instr.Opcode == codegen.OpConst && entry != "" && instr.Args[0] == "la función '"+entry+"' no es payable":
+			guarded[entry] = true
+		}
+	}
+	if guarded["deposit"] || !guarded["withdraw"] || !guarded["deposited"] {
+		t.Fatalf("unexpected value guards: %v", guarded)
+	}
+	if !seen[codegen.OpTransfer] {
+		t.Fatalf("expected TRANSFER in the runtime code")
+	}
+
+	tests := map[string]string{
+		"value in non-payable": "pub func f(): uint64 { return value(); }",
+		"private payable":      "priv func f() payable: void { }",
+		"transfer arguments":   "pub func f(): void { transfer(1, caller()); }",
+		"view transfer":        "pub func f(to: address) view: void { transfer(to, 1); }",
+	}
+	for name, fn := range tests {
+		src := `pragma: "1.0.0";
+		class contract Vault {
+			` + fn + `
+		}
+		`
+		if _, err := CompileWithOptions(src, Options{InMemory: true}); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+
+	// Internal calls skip the guard of the callee: a payable function can call a
+	// non-payable pub function while receiving value.
+	contracts, err = CompileWithOptions(`pragma: "1.0.0";
+class contract Counter {
+    pub storage hits(id: uint64): uint64;
+    pub func deposit() payable: void {
+        bump();
+    }
+    pub func bump(): void {
+        hits(0): hits(0) + 1;
+    }
+}`, Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := ""
+	for _, instr := range contracts["Counter"].Bytecode {
+		switch {
+		case instr.Opcode == codegen.OpFunc:
+			current = instr.Args[0].(string)
+		case instr.Opcode == codegen.OpEnd && instr.Args[0] == "FUNC":
+			current = ""
+		case instr.Opcode == codegen.OpCallValue && current != "":
+			t.Fatalf("the value guard must not run inside the body of '%s'", current)
+		case instr.Opcode == codegen.OpLoad && current == "deposit" && instr.Args[0] != "bump":
+			t.Fatalf("unexpected load %s in deposit", instr.Raw)
+		}
+	}
+
+	// value() is available to private helpers, which run with the caller's value.
+	_, err = CompileWithOptions(`pragma: "1.0.0";
+class contract Vault {
+    pub func deposit() payable: uint64 {
+        return _received();
+    }
+    priv func _received(): uint64 {
+        return value();
+    }
+}`, Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A contract function named like a builtin takes precedence over it.
+	contracts, err = CompileWithOptions(`pragma: "1.0.0";
+class contract Token {
+    pub storage balances(account: address): uint64;
+    pub func transfer(to: address, amount: uint64): bool {
+        balances(to): balances(to) + amount;
+        return true;
+    }
+    pub func airdrop(to: address): bool {
+        return transfer(to, 1);
+    }
+}`, Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, instr := range contracts["Token"].Bytecode {
+		if instr.Opcode == codegen.OpTransfer {
+			t.Fatalf("expected transfer to call the contract function")
+		}
+	}
+
+	// So does a storage, and a member inherited from an imported class.
+	fsys := NewMemoryFS(map[string]string{
+		"base.ry": `pragma: "1.0.0";
+			class contract Base { pub func transfer(to: address, amount: uint64): bool { return true; } }`,
+	})
+	contracts, err = CompileWithOptions(`pragma: "1.0.0";
+import { Base } from "./base.ry";
+class contract Ledger is Base {
+    pub storage value(k: uint64): uint64;
+    pub func get(k: uint64): uint64 {
+        return value(k);
+    }
+    pub func send(to: address): bool {
+        return transfer(to, 1);
+    }
+}`, Options{InMemory: true, FS: fsys, Filename: "main.ry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loads := make(map[interface{}]bool)
+	for _, instr := range contracts["Ledger"].Bytecode {
+		if instr.Opcode == codegen.OpTransfer {
+			t.Fatalf("expected transfer to call the inherited function")
+		}
+		if instr.Opcode == codegen.OpLoad {
+			loads[instr.Args[0]] = true
+		}
+	}
+	if !loads["value"] || !loads["transfer"] {
+		t.Fatalf("expected value and transfer to be member calls, got %v", loads)
+	}
+}
+
+func TestCompileConstAndImmutable(t *testing.T) {
+	input, err := os.ReadFile("../example/immutable.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := CompileWithOptions(string(input), Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Fees"]
+
+	// Only the regular variable takes a storage slot.
+	if len(contract.Storage) != 1 || contract.Storage[0].Name != "collected" {
+		t.Fatalf("unexpected storage layout: %+v", contract.Storage)
+	}
+
+	// The init code sets both immutables, the constructor argument last.
+	var set []interface{}
+	for _, instr := range contract.InitBytecode {
+		if instr.Opcode == codegen.OpSetImmutable {
+			set = append(set, instr.Args[0])
+		}
+		if instr.Opcode == codegen.OpStore && instr.Args[0] != "collected" {
+			t.Fatalf("unexpected storage write in the init code: %v", instr.Raw)
+		}
+	}
+	if fmt.Sprint(set) != "[createdAt owner]" {
+		t.Fatalf("unexpected immutables set at deployment: %v", set)
+	}
+
+	// The runtime code inlines the constants and reads the immutables from the code.
+	for _, instr := range contract.Bytecode {
+		if instr.Opcode == codegen.OpLoad && (instr.Args[0] == "rate" || instr.Args[0] == "maxFee" || instr.Args[0] == "owner") {
+			t.Fatalf("expected %v not to be loaded from storage", instr.Args[0])
+		}
+	}
+	seen := map[string]bool{}
+	for i, instr := range contract.Bytecode {
+		switch {
+		case instr.Opcode == codegen.OpImmutable:
+			seen[instr.Args[0].(string)] = true
+		case instr.Opcode == codegen.OpMul && contract.Bytecode[i-1].Opcode == codegen.OpConst && contract.Bytecode[i-1].Args[0] == uint64(3):
+			seen["rate"] = true
+		}
+	}
+	if !seen["owner"] || !seen["rate"] {
+		t.Fatalf("expected the inlined constant and the immutable read, got %v", seen)
+	}
+
+	tests := []struct {
+		members string
+		want    string
+	}{
+		{`const uint64 fee: 1;
+    pub func f(): void {
+        fee: 2;
+    }`, "a.ry:5:9: codegen: no se puede asignar la constante 'fee'"},
+		{`immutable address owner;
+    constructor() {
+        owner: caller();
+        owner: caller();
+    }`, "a.ry:6:9: codegen: el inmutable 'owner' ya tiene valor y no se puede reasignar"},
+		{`immutable uint64 start: block.number;
+    constructor() {
+        start: 1;
+    }`, "a.ry:5:9: codegen: el inmutable 'start' ya tiene valor y no se puede reasignar"},
+		{`immutable address owner;
+    constructor() {
+        owner: caller();
+    }
+    pub func f(): void {
+        owner: caller();
+    }`, "a.ry:8:9: codegen: el inmutable 'owner' solo puede asignarse en el constructor"},
+		{`immutable uint64 a;
+    constructor(x: uint64) {
+        uint64 y: a;
+        a: x;
+    }`, "a.ry:5:19: codegen: el inmutable 'a' se lee antes de asignarse"},
+		{`immutable uint64 a;
+    constructor(x: uint64) {
+        a: a + x;
+    }`, "a.ry:5:12: codegen: el inmutable 'a' se lee antes de asignarse"},
+		{`immutable uint64 a;
+    uint64 copy: a;
+    constructor(x: uint64) {
+        a: x;
+    }`, "a.ry:4:18: codegen: el inmutable 'a' se lee antes de asignarse"},
+		{`immutable uint64 a;
+    uint64 doubled;
+    constructor(x: uint64) {
+        a: x;
+        doubled: a + a;
+    }`, ""},
+		{`immutable address owner;`, "a.ry:3:15: codegen: el inmutable 'owner' no se asigna en el constructor"},
+		{`const uint64 fee;`, "a.ry:3:11: codegen: la constante 'fee' necesita un valor"},
+		{`const uint64 fee: block.number;`, "a.ry:3:11: codegen: el valor de la constante 'fee' no se conoce en compilación"},
+		{`const uint64 a: b + 1;
+    const uint64 b: a;`, "a.ry:3:11: codegen: la constante 'a' depende de sí misma"},
+		{`const uint64 fee: true;`, "a.ry:3:11: codegen: no se puede asignar bool a 'fee' de tipo uint64"},
+		{`uint64 count;
+    pub func f(): uint64 {
+        count: count + 1;
+        return count;
+    }`, ""},
+	}
+	for _, tt := range tests {
+		_, err := CompileWithOptions(`pragma: "1.0.0";
+class contract A {
+    `+tt.members+`
+}`, Options{InMemory: true, Filename: "a.ry"})
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			continue
+		}
+		if diags := AsDiagnostics(err); len(diags) != 1 || diags[0].Error() != tt.want {
+			t.Errorf("unexpected diagnostics: %v\nwant %s", diags, tt.want)
+		}
+	}
+}
+
+func TestCompileGetters(t *testing.T) {
+	contracts, err := CompileWithOptions(`pragma: "1.0.0";
+class contract Token {
+    pub uint64 count;
+    pub uint64 supply: 1000;
+    uint64 hidden;
+    pub const uint64 decimals: 8;
+    pub immutable address owner;
+    pub storage balances(account: address): uint64;
+    pub storage allowance(owner: address, spender: address): uint64;
+    storage nonces(account: address): uint64;
+    pub uint64 total;
+
+    constructor() {
+        owner: caller();
+    }
+
+    pub func total(): uint64 {
+        return count + supply;
+    }
+}`, Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := contracts["Token"]
+
+	abi := make(map[string]codegen.ABIFunction)
+	for _, entry := range contract.ABI {
+		if entry.Type == "function" {
+			if _, dup := abi[entry.Name]; dup {
+				t.Fatalf("duplicate ABI entry for %s", entry.Name)
+			}
+			abi[entry.Name] = entry
+		}
+	}
+	for _, name := range []string{"hidden", "nonces"} {
+		if _, ok := abi[name]; ok {
+			t.Fatalf("expected no getter for the private %s", name)
+		}
+	}
+	tests := []struct {
+		name    string
+		inputs  []string
+		output  string
+		mutable string
+	}{
+		{"count", nil, "uint64", codegen.MutabilityView},
+		{"supply", nil, "uint64", codegen.MutabilityView},
+		{"decimals", nil, "uint64", codegen.MutabilityPure},
+		{"owner", nil, "address", codegen.MutabilityView},
+		{"balances", []string{"address"}, "uint64", codegen.MutabilityView},
+		{"allowance", []string{"address", "address"}, "uint64", codegen.MutabilityView},
+		{"total", nil, "uint64", codegen.MutabilityView}, // La función escrita a mano.
+	}
+	for _, tt := range tests {
+		entry, ok := abi[tt.name]
+		if !ok {
+			t.Fatalf("expected a getter for %s", tt.name)
+		}
+		var inputs []string
+		for _, input := range entry.Inputs {
+			inputs = append(inputs, input.Type)
+		}
+		if fmt.Sprint(inputs) != fmt.Sprint(tt.inputs) || len(entry.Outputs) != 1 || entry.Outputs[0].Type != tt.output ||
+			entry.StateMut != tt.mutable || entry.Visibility != "public" || entry.Selector != codegen.NewSelector(tt.name, tt.inputs).String() {
+			t.Fatalf("unexpected ABI entry for %s: %+v", tt.name, entry)
+		}
+	}
+
+	// Each getter is a function in the runtime code that returns the stored value.
+	funcs := make(map[string][]codegen.Opcode)
+	current := ""
+	for _, instr := range contract.Bytecode {
+		switch instr.Opcode {
+		case codegen.OpFunc:
+			current = instr.Args[0].(string)
+		case codegen.OpLoad, codegen.OpImmutable, codegen.OpReturn:
+			if current != "" {
+				funcs[current] = append(funcs[current], instr.Opcode)
+			}
+		}
+	}
+	if fmt.Sprint(funcs["allowance"]) != fmt.Sprint([]codegen.Opcode{codegen.OpLoad, codegen.OpLoad, codegen.OpLoad, codegen.OpReturn}) ||
+		fmt.Sprint(funcs["owner"]) != fmt.Sprint([]codegen.Opcode{codegen.OpImmutable, codegen.OpReturn}) ||
+		fmt.Sprint(funcs["decimals"]) != fmt.Sprint([]codegen.Opcode{codegen.OpReturn}) {
+		t.Fatalf("unexpected getter code: %v", funcs)
+	}
+}
+
+func TestCompileGettersImplementInterfaces(t *testing.T) {
+	input := `pragma: "1.0.0";
+class interface ICounter {
+    pub func count() view: uint64;
+    pub func balances(account: address) view: uint64;
+}
+
+class contract Counter is ICounter {
+    pub uint64 count;
+    pub storage balances(account: address): uint64;
+}`
+	contracts, err := CompileWithOptions(input, Options{InMemory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	implemented := 0
+	for _, entry := range contracts["Counter"].ABI {
+		if entry.Name == "count" || entry.Name == "balances" {
+			implemented++
+		}
+	}
+	if implemented != 2 {
+		t.Fatalf("expected the getters of count and balances, got %+v", contracts["Counter"].ABI)
+	}
+
+	tests := map[string]struct{ from, to, message string }{
+		"private variable": {"pub uint64 count;", "uint64 count;", "el contrato 'Counter' no implementa 'ICounter.count'"},
+		"wrong type":       {"pub uint64 count;", "pub bool count;", "'Counter.count' no tiene la firma de 'ICounter.count'"},
+	}
+	for name, tt := range tests {
+		_, err := CompileWithOptions(strings.Replace(input, tt.from, tt.to, 1), Options{InMemory: true})
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Fatalf("%s: expected %q, got %v", name, tt.message, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/codegen"
+	"github.com/polarysfoundation/ryot/doc"
+	"github.com/polarysfoundation/ryot/flow"
+	"github.com/polarysfoundation/ryot/parser"
+)
+
+// Severidades de un diagnóstico.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic es un error o aviso de compilación con su posición en el código
+// fuente. Line y Column empiezan en 1 y valen 0 si la posición no se conoce.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Error devuelve el mensaje precedido por archivo:línea:columna, según se conozcan.
+func (d Diagnostic) Error() string {
+	var prefix []string
+	if d.File != "" {
+		prefix = append(prefix, d.File)
+	}
+	if d.Line > 0 {
+		prefix = append(prefix, fmt.Sprintf("%d:%d", d.Line, d.Column))
+	}
+	if len(prefix) == 0 {
+		return d.Message
+	}
+	return strings.Join(prefix, ":") + ": " + d.Message
+}
+
+// Diagnostics es el error que devuelve el compilador cuando encuentra uno o más
+// problemas con posición conocida.
+type Diagnostics []Diagnostic
+
+// Error devuelve todos los diagnósticos separados por "; ".
+func (d Diagnostics) Error() string {
+	msgs := make([]string, 0, len(d))
+	for _, diag := range d {
+		msgs = append(msgs, diag.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// syntaxDiagnostics convierte los errores del parser de file en diagnósticos.
+func syntaxDiagnostics(file string, errs []parser.Error) Diagnostics {
+	diags := make(Diagnostics, 0, len(errs))
+	for _, err := range errs {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			File:     file,
+			Line:     err.Line,
+			Column:   err.Column,
+			Message:  err.Message,
+		})
+	}
+	return diags
+}
+
+// AsDiagnostics convierte cualquier error del compilador en diagnósticos. Los
+// errores sin posición se convierten en un único diagnóstico sin archivo ni línea.
+func AsDiagnostics(err error) Diagnostics {
+	if err == nil {
+		return nil
+	}
+	var diags Diagnostics
+	if errors.As(err, &diags) {
+		return diags
+	}
+	var diag Diagnostic
+	if errors.As(err, &diag) {
+		return Diagnostics{diag}
+	}
+	return Diagnostics{{Severity: SeverityError, Message: err.Error()}}
+}
+
+// codegenDiagnostic convierte un error de generación en un diagnóstico. files
+// indica el archivo de cada clase importada; el resto pertenece a file.
+func codegenDiagnostic(err error, file string, files map[string]string) error {
+	var positioned *codegen.Error
+	if !errors.As(err, &positioned) {
+		return fmt.Errorf("error de generación de código: %w", err)
+	}
+	if origin, ok := files[positioned.Class]; ok {
+		file = origin
+	}
+	return Diagnostic{
+		Severity: SeverityError,
+		File:     file,
+		Line:     positioned.Line,
+		Column:   positioned.Column,
+		Message:  positioned.Error(),
+	}
+}
+
+// docDiagnostic convierte un comentario /// incorrecto en un diagnóstico. files
+// indica el archivo de cada clase importada; el resto pertenece a file.
+func docDiagnostic(err error, file string, files map[string]string) error {
+	var positioned *doc.Error
+	if !errors.As(err, &positioned) {
+		return err
+	}
+	if origin, ok := files[positioned.Class]; ok {
+		file = origin
+	}
+	return Diagnostic{
+		Severity: SeverityError,
+		File:     file,
+		Line:     positioned.Line,
+		Column:   positioned.Column,
+		Message:  positioned.Error(),
+	}
+}
+
+// flowDiagnostics convierte los problemas de flujo de control en diagnósticos:
+// las funciones que pueden terminar sin return son errores y el código
+// inalcanzable son avisos, agrupados por clase. files indica el archivo de cada
+// clase importada; el resto pertenece a file.
+func flowDiagnostics(issues []flow.Issue, file string, files map[string]string) (Diagnostics, map[string][]Diagnostic) {
+	var errs Diagnostics
+	warnings := make(map[string][]Diagnostic)
+	for _, issue := range issues {
+		diag := Diagnostic{
+			Severity: SeverityError,
+			File:     file,
+			Line:     issue.Line,
+			Column:   issue.Column,
+			Message:  issue.Error(),
+		}
+		if origin, ok := files[issue.Class]; ok {
+			diag.File = origin
+		}
+		if issue.Kind == flow.Unreachable {
+			diag.Severity = SeverityWarning
+			warnings[issue.Class] = append(warnings[issue.Class], diag)
+			continue
+		}
+		errs = append(errs, diag)
+	}
+	return errs, warnings
+}
+
+// Warnings devuelve los avisos de los contratos compilados que no vienen de un
+// archivo importado, ordenados por posición.
+func Warnings(contracts map[string]*CompiledContract) Diagnostics {
+	var warnings Diagnostics
+	for _, contract := range contracts {
+		if !contract.Imported {
+			warnings = append(warnings, contract.Warnings...)
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		a, b := warnings[i], warnings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return warnings
+}
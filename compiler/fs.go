@@ -0,0 +1,74 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSystem abstrae el acceso a archivos del compilador: la lectura de las
+// importaciones y la escritura de los artefactos.
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	MkdirAll(dir string) error
+}
+
+// OSFileSystem usa el sistema de archivos del sistema operativo.
+type OSFileSystem struct{}
+
+// ReadFile lee el archivo indicado.
+func (OSFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile escribe el archivo indicado, reemplazándolo si existe.
+func (OSFileSystem) WriteFile(name string, data []byte) error {
+	return os.WriteFile(name, data, 0644)
+}
+
+// MkdirAll crea el directorio indicado y sus padres.
+func (OSFileSystem) MkdirAll(dir string) error {
+	return os.MkdirAll(dir, os.ModePerm)
+}
+
+// MemoryFS es un sistema de archivos en memoria, útil para pruebas y para
+// integrar el compilador en servidores. Es seguro para uso concurrente.
+type MemoryFS struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemoryFS crea un MemoryFS con los archivos indicados (ruta -> contenido).
+func NewMemoryFS(files map[string]string) *MemoryFS {
+	m := &MemoryFS{files: make(map[string][]byte)}
+	for name, content := range files {
+		m.files[filepath.Clean(name)] = []byte(content)
+	}
+	return m
+}
+
+// ReadFile devuelve una copia del contenido del archivo indicado.
+func (m *MemoryFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("open %s: %w", name, os.ErrNotExist)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile guarda una copia de data en el archivo indicado.
+func (m *MemoryFS) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filepath.Clean(name)] = append([]byte(nil), data...)
+	return nil
+}
+
+// MkdirAll no hace nada: los directorios están implícitos en las rutas.
+func (m *MemoryFS) MkdirAll(dir string) error {
+	return nil
+}
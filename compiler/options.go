@@ -0,0 +1,71 @@
+package compiler
+
+import "github.com/polarysfoundation/ryot/parser"
+
+// Logger recibe los mensajes de diagnóstico del compilador. *log.Logger lo
+// implementa. Por defecto los mensajes se descartan.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// nopLogger descarta todos los mensajes.
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, args ...interface{}) {}
+
+// Artifacts selecciona los archivos de salida que genera el compilador.
+type Artifacts uint8
+
+const (
+	ArtifactABI      Artifacts = 1 << iota // abi.json
+	ArtifactRYC                            // bytecode.ryc
+	ArtifactRYBC                           // bytecode.rybc
+	ArtifactInitRYC                        // init.ryc
+	ArtifactInitRYBC                       // init.rybc
+	ArtifactUserDoc                        // userdoc.json
+	ArtifactDevDoc                         // devdoc.json
+	ArtifactMarkdown                       // doc.md; no forma parte de AllArtifacts.
+
+	AllArtifacts = ArtifactABI | ArtifactRYC | ArtifactRYBC | ArtifactInitRYC | ArtifactInitRYBC | ArtifactUserDoc | ArtifactDevDoc
+)
+
+// Options configura una compilación. El valor cero compila como Compile: escribe
+// todos los artefactos en ./artifacts/ usando el sistema de archivos del sistema operativo.
+type Options struct {
+	OutputDir  string     // Directorio donde se escriben los artefactos; por defecto ./artifacts/.
+	InMemory   bool       // Si es true no se escribe ningún archivo; los artefactos solo se devuelven en CompiledContract.Artifacts.
+	Artifacts  Artifacts  // Artefactos que se generan; cero equivale a AllArtifacts.
+	FS         FileSystem // Sistema de archivos para leer importaciones y escribir artefactos; por defecto OSFileSystem.
+	Filename   string     // Ruta del código fuente, desde la que se resuelven las importaciones relativas.
+	SearchPath []string   // Directorios donde se buscan las importaciones no relativas.
+	Logger     Logger     // Destino de los mensajes de diagnóstico; por defecto no se muestra nada.
+	Trace      bool       // Envía también al Logger la traza del parser, para depurar la gramática.
+}
+
+// withDefaults devuelve una copia de las opciones con los valores por defecto aplicados.
+func (o Options) withDefaults() Options {
+	if o.OutputDir == "" {
+		o.OutputDir = path
+	}
+	if o.Artifacts == 0 {
+		o.Artifacts = AllArtifacts
+	}
+	if o.FS == nil {
+		o.FS = OSFileSystem{}
+	}
+	if o.Filename == "" {
+		o.Filename = "<input>"
+	}
+	if o.Logger == nil {
+		o.Logger = nopLogger{}
+	}
+	return o
+}
+
+// parserOptions devuelve las opciones con las que se crean los parsers de la compilación.
+func (o Options) parserOptions() []parser.Option {
+	if !o.Trace {
+		return nil
+	}
+	return []parser.Option{parser.WithTracer(parser.TracerFunc(o.Logger.Printf))}
+}
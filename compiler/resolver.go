@@ -0,0 +1,273 @@
+package compiler
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/parser"
+)
+
+// Resolver localiza los archivos importados y los combina con el programa
+// principal en una sola unidad de compilación.
+//
+// Las rutas que empiezan por ./ o ../ se resuelven desde el directorio del archivo
+// que importa; el resto se buscan, en orden, en los directorios de SearchPath.
+type Resolver struct {
+	SearchPath []string   // Directorios donde se buscan las importaciones no relativas.
+	FS         FileSystem // Sistema de archivos del que se leen los módulos.
+
+	parserOptions []parser.Option // Opciones de los parsers de los módulos, p. ej. la traza.
+}
+
+// NewResolver crea un Resolver sobre el sistema de archivos del sistema operativo
+// con la ruta de búsqueda indicada.
+func NewResolver(searchPath ...string) *Resolver {
+	return &Resolver{SearchPath: searchPath, FS: OSFileSystem{}}
+}
+
+// Resolve devuelve la ruta del archivo importado como importPath desde el directorio dir.
+func (r *Resolver) Resolve(dir string, importPath string) (string, error) {
+	var candidates []string
+	switch {
+	case filepath.IsAbs(importPath):
+		candidates = []string{importPath}
+	case strings.HasPrefix(importPath, "./") || strings.HasPrefix(importPath, "../"):
+		candidates = []string{filepath.Join(dir, importPath)}
+	default:
+		for _, searchDir := range r.SearchPath {
+			candidates = append(candidates, filepath.Join(searchDir, importPath))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := r.FS.ReadFile(candidate); err == nil {
+			return filepath.Clean(candidate), nil
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("compiler: no se encontró '%s': la ruta de búsqueda está vacía", importPath)
+	}
+	return "", fmt.Errorf("compiler: no se encontró '%s' (buscado en: %s)", importPath, strings.Join(candidates, ", "))
+}
+
+// symbol es una clase visible en un archivo junto con el archivo que la declara.
+type symbol struct {
+	class *ast.ClassStatement
+	file  string
+}
+
+// resolution guarda el estado de la resolución de una unidad de compilación.
+type resolution struct {
+	resolver *Resolver
+	scopes   map[string]map[string]symbol   // Clases visibles en cada archivo ya resuelto.
+	stack    []string                       // Archivos en resolución, para detectar ciclos.
+	classes  []*ast.ClassStatement          // Clases importadas, con sus dependencias primero.
+	included map[*ast.ClassStatement]bool   // Clases ya añadidas o en curso de añadirse.
+	unit     map[string]symbol              // Clases de la unidad de compilación por nombre.
+	files    map[string]string              // Archivo que declara cada clase importada.
+	comments map[ast.Node]*ast.CommentGroup // Comentarios de los módulos importados.
+}
+
+// resolveImports sustituye las importaciones del programa por las clases importadas.
+// file es la ruta del programa; las rutas relativas se resuelven desde su directorio.
+// Devuelve también el archivo que declara cada clase importada.
+func (r *Resolver) resolveImports(program *ast.Program, file string) (*ast.Program, map[string]string, error) {
+	res := &resolution{
+		resolver: r,
+		scopes:   make(map[string]map[string]symbol),
+		files:    make(map[string]string),
+		included: make(map[*ast.ClassStatement]bool),
+		unit:     make(map[string]symbol),
+		comments: make(map[ast.Node]*ast.CommentGroup),
+	}
+
+	for _, stmt := range program.Statements {
+		if class, ok := stmt.(*ast.ClassStatement); ok {
+			res.unit[class.Name] = symbol{class: class, file: filepath.Clean(file)}
+		}
+	}
+	if _, err := res.resolve(filepath.Clean(file), program); err != nil {
+		return nil, nil, err
+	}
+
+	resolved := &ast.Program{Comments: res.comments}
+	for node, group := range program.Comments {
+		resolved.Comments[node] = group
+	}
+	for _, stmt := range program.Statements {
+		if _, ok := stmt.(*ast.ImportStatement); ok {
+			continue
+		}
+		resolved.Statements = append(resolved.Statements, stmt)
+		if _, ok := stmt.(*ast.PragmaStatement); ok {
+			// Las clases importadas van tras el pragma y antes de las del programa.
+			for _, class := range res.classes {
+				resolved.Statements = append(resolved.Statements, class)
+			}
+		}
+	}
+	// Las clases pueden llamar a miembros heredados de una clase importada que
+	// se llaman como una función predefinida.
+	parser.ResolveMemberCalls(resolved)
+	return resolved, res.files, nil
+}
+
+// resolve devuelve las clases visibles en file: las que declara y las que importa.
+func (res *resolution) resolve(file string, program *ast.Program) (map[string]symbol, error) {
+	if scope, ok := res.scopes[file]; ok {
+		return scope, nil
+	}
+	if i := slices.Index(res.stack, file); i >= 0 {
+		cycle := append(slices.Clone(res.stack[i:]), file)
+		for j := range cycle {
+			cycle[j] = filepath.Base(cycle[j])
+		}
+		return nil, fmt.Errorf("compiler: importación circular: %s", strings.Join(cycle, " -> "))
+	}
+	res.stack = append(res.stack, file)
+	defer func() { res.stack = res.stack[:len(res.stack)-1] }()
+
+	scope := make(map[string]symbol)
+	declare := func(s symbol) error {
+		if prev, ok := scope[s.class.Name]; ok && prev.class != s.class {
+			return fmt.Errorf("compiler: '%s' de %s choca con '%s' de %s", s.class.Name, filepath.Base(s.file), s.class.Name, filepath.Base(prev.file))
+		}
+		scope[s.class.Name] = s
+		return nil
+	}
+
+	for _, stmt := range program.Statements {
+		if class, ok := stmt.(*ast.ClassStatement); ok {
+			if err := declare(symbol{class: class, file: file}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, stmt := range program.Statements {
+		imp, ok := stmt.(*ast.ImportStatement)
+		if !ok {
+			continue
+		}
+
+		target, err := res.resolver.Resolve(filepath.Dir(file), imp.Path)
+		if err != nil {
+			return nil, err
+		}
+		module, err := res.parse(target)
+		if err != nil {
+			return nil, err
+		}
+		exported, err := res.resolve(target, module)
+		if err != nil {
+			return nil, err
+		}
+
+		names := imp.Names
+		if len(names) == 0 {
+			for name := range exported {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		for _, name := range names {
+			s, ok := exported[name]
+			if !ok {
+				return nil, fmt.Errorf("compiler: %s no declara '%s'", imp.Path, name)
+			}
+			if err := declare(s); err != nil {
+				return nil, err
+			}
+			if err := res.include(s); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res.scopes[file] = scope
+	return scope, nil
+}
+
+// include añade una clase importada a la unidad de compilación, precedida por
+// las clases de las que depende: sus padres, las interfaces a las que llama y
+// los contratos que despliega. Las dependencias se buscan entre las clases
+// visibles en el archivo que la declara.
+//
+// Las dependencias no se importan por nombre en el programa, así que include
+// comprueba que ninguna coincida con otra clase de la unidad de compilación.
+func (res *resolution) include(s symbol) error {
+	if res.included[s.class] {
+		return nil
+	}
+	res.included[s.class] = true
+
+	if prev, ok := res.unit[s.class.Name]; ok && prev.class != s.class {
+		return fmt.Errorf("compiler: '%s' de %s choca con '%s' de %s", s.class.Name, filepath.Base(s.file), s.class.Name, filepath.Base(prev.file))
+	}
+	res.unit[s.class.Name] = s
+
+	scope := res.scopes[s.file]
+	for _, name := range references(s.class) {
+		if dep, ok := scope[name]; ok {
+			if err := res.include(dep); err != nil {
+				return err
+			}
+		}
+	}
+	res.classes = append(res.classes, s.class)
+	res.files[s.class.Name] = s.file
+	return nil
+}
+
+// references devuelve los nombres de las clases que usa class, en orden de aparición.
+func references(class *ast.ClassStatement) []string {
+	names := slices.Clone(class.Parents)
+	ast.Inspect(class, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ExternalCallExpression:
+			names = append(names, n.Interface)
+		case *ast.CreateExpression:
+			names = append(names, n.Contract)
+		}
+		return true
+	})
+	return names
+}
+
+// parse lee y analiza un archivo importado, comprobando que su pragma admita este compilador.
+func (res *resolution) parse(file string) (*ast.Program, error) {
+	input, err := res.resolver.FS.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("compiler: error al leer %s: %w", file, err)
+	}
+
+	p := parser.New(lexer.New(string(input), lexer.WithComments()), res.resolver.parserOptions...)
+	program, ok := p.ParseProgram().(*ast.Program)
+	if !ok {
+		return nil, fmt.Errorf("compiler: %s no es un programa", file)
+	}
+	if len(p.SyntaxErrors()) > 0 {
+		return nil, syntaxDiagnostics(file, p.SyntaxErrors())
+	}
+
+	if len(program.Statements) == 0 {
+		return nil, fmt.Errorf("compiler: %s está vacío", filepath.Base(file))
+	}
+	pragma, ok := program.Statements[0].(*ast.PragmaStatement)
+	if !ok {
+		return nil, fmt.Errorf("compiler: %s debe empezar por un pragma", filepath.Base(file))
+	}
+	if err := checkPragma(file, pragma); err != nil {
+		return nil, err
+	}
+
+	for node, group := range program.Comments {
+		res.comments[node] = group
+	}
+	return program, nil
+}
@@ -0,0 +1,230 @@
+package compiler
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/codegen"
+	"github.com/polarysfoundation/ryot/doc"
+)
+
+// Language es el único lenguaje que acepta la interfaz JSON estándar.
+const Language = "Ryot"
+
+// Salidas que pueden pedirse en StandardSettings.OutputSelection. "*" las selecciona todas.
+const (
+	OutputABI           = "abi"
+	OutputBytecode      = "bytecode"
+	OutputInitBytecode  = "initBytecode"
+	OutputRYC           = "ryc"
+	OutputInitRYC       = "initRyc"
+	OutputStorageLayout = "storageLayout"
+	OutputUserDoc       = "userdoc"
+	OutputDevDoc        = "devdoc"
+)
+
+// StandardInput es la entrada de la interfaz JSON estándar: los códigos fuente
+// indexados por ruta y los ajustes de compilación.
+type StandardInput struct {
+	Language string                    `json:"language"`
+	Sources  map[string]StandardSource `json:"sources"`
+	Settings StandardSettings          `json:"settings"`
+}
+
+// StandardSource es el contenido de un archivo fuente.
+type StandardSource struct {
+	Content string `json:"content"`
+}
+
+// StandardSettings ajusta la compilación. Las importaciones se resuelven entre los
+// archivos de Sources; SearchPath son directorios dentro de ese mismo conjunto.
+type StandardSettings struct {
+	SearchPath []string `json:"searchPath,omitempty"`
+	// OutputSelection indica las salidas de cada archivo, o de todos con la clave "*".
+	// Si está vacío se generan todas.
+	OutputSelection map[string][]string `json:"outputSelection,omitempty"`
+}
+
+// StandardOutput es la salida de la interfaz JSON estándar: los diagnósticos y
+// los contratos compilados, indexados por archivo y por nombre. Errors incluye
+// también los avisos, con severidad "warning".
+type StandardOutput struct {
+	Version   string                                 `json:"version"`
+	Errors    []Diagnostic                           `json:"errors,omitempty"`
+	Contracts map[string]map[string]StandardContract `json:"contracts,omitempty"`
+}
+
+// StandardContract contiene las salidas seleccionadas de un contrato. El bytecode
+// es el RYBC codificado en hexadecimal.
+type StandardContract struct {
+	Interface     bool                   `json:"interface,omitempty"`
+	ABI           codegen.ABI            `json:"abi,omitempty"`
+	Bytecode      string                 `json:"bytecode,omitempty"`
+	InitBytecode  string                 `json:"initBytecode,omitempty"`
+	RYC           string                 `json:"ryc,omitempty"`
+	InitRYC       string                 `json:"initRyc,omitempty"`
+	StorageLayout []codegen.StorageEntry `json:"storageLayout,omitempty"`
+	UserDoc       *doc.UserDoc           `json:"userdoc,omitempty"`
+	DevDoc        *doc.DevDoc            `json:"devdoc,omitempty"`
+}
+
+// CompileStandardJSON compila una entrada JSON estándar y devuelve la salida JSON.
+// Los problemas de la entrada y de la compilación se devuelven en el campo errors
+// de la salida; el error solo indica que no se pudo serializar la salida.
+func CompileStandardJSON(input []byte) ([]byte, error) {
+	var in StandardInput
+	var out StandardOutput
+	if err := json.Unmarshal(input, &in); err != nil {
+		out = StandardOutput{Version: Version, Errors: []Diagnostic{{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("compiler: entrada JSON inválida: %v", err),
+		}}}
+	} else {
+		out = CompileStandard(in)
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// CompileStandard compila cada archivo de la entrada sin escribir nada en disco.
+// Los contratos importados solo aparecen en la salida del archivo que los declara.
+func CompileStandard(in StandardInput) StandardOutput {
+	out := StandardOutput{Version: Version}
+	fail := func(format string, args ...interface{}) StandardOutput {
+		out.Errors = append(out.Errors, Diagnostic{Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+		return out
+	}
+	if in.Language != "" && in.Language != Language {
+		return fail("compiler: lenguaje no soportado '%s', se esperaba '%s'", in.Language, Language)
+	}
+	if len(in.Sources) == 0 {
+		return fail("compiler: no se indicó ningún archivo en sources")
+	}
+
+	files := make(map[string]string, len(in.Sources))
+	names := make([]string, 0, len(in.Sources))
+	for name, source := range in.Sources {
+		files[name] = source.Content
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fsys := NewMemoryFS(files)
+
+	for _, name := range names {
+		selected := in.Settings.selection(name)
+		if len(selected) == 0 {
+			continue
+		}
+		contracts, err := CompileWithOptions(files[name], Options{
+			InMemory:   true,
+			Artifacts:  selected.artifacts(),
+			FS:         fsys,
+			Filename:   name,
+			SearchPath: in.Settings.SearchPath,
+		})
+		if err != nil {
+			for _, diag := range AsDiagnostics(err) {
+				if diag.File == "" {
+					diag.File = name
+				}
+				out.Errors = append(out.Errors, diag)
+			}
+			continue
+		}
+		out.Errors = append(out.Errors, Warnings(contracts)...)
+
+		for _, contract := range contracts {
+			if contract.Imported {
+				continue
+			}
+			if out.Contracts == nil {
+				out.Contracts = make(map[string]map[string]StandardContract)
+			}
+			if out.Contracts[name] == nil {
+				out.Contracts[name] = make(map[string]StandardContract)
+			}
+			out.Contracts[name][contract.Name] = selected.contract(contract)
+		}
+	}
+	return out
+}
+
+// outputSelection es la lista de salidas pedidas para un archivo.
+type outputSelection []string
+
+// selection devuelve las salidas pedidas para file. Sin OutputSelection se piden todas.
+func (s StandardSettings) selection(file string) outputSelection {
+	if len(s.OutputSelection) == 0 {
+		return outputSelection{"*"}
+	}
+	var selected outputSelection
+	selected = append(selected, s.OutputSelection["*"]...)
+	selected = append(selected, s.OutputSelection[file]...)
+	return selected
+}
+
+func (s outputSelection) has(output string) bool {
+	return slices.Contains(s, "*") || slices.Contains(s, output)
+}
+
+// artifacts devuelve los artefactos que hay que generar para las salidas pedidas.
+func (s outputSelection) artifacts() Artifacts {
+	var selected Artifacts
+	if s.has(OutputABI) {
+		selected |= ArtifactABI
+	}
+	if s.has(OutputBytecode) {
+		selected |= ArtifactRYBC
+	}
+	if s.has(OutputInitBytecode) {
+		selected |= ArtifactInitRYBC
+	}
+	if s.has(OutputRYC) {
+		selected |= ArtifactRYC
+	}
+	if s.has(OutputInitRYC) {
+		selected |= ArtifactInitRYC
+	}
+	if selected == 0 {
+		selected = ArtifactABI // Artifacts cero equivale a todos; la ABI es la más barata.
+	}
+	return selected
+}
+
+// contract construye la salida de un contrato con los campos pedidos.
+func (s outputSelection) contract(c *CompiledContract) StandardContract {
+	out := StandardContract{Interface: c.IsInterface}
+	if s.has(OutputABI) {
+		out.ABI = c.ABI
+	}
+	if s.has(OutputStorageLayout) {
+		out.StorageLayout = c.Storage
+	}
+	if s.has(OutputUserDoc) && c.Doc != nil {
+		userdoc := c.Doc.UserDoc()
+		out.UserDoc = &userdoc
+	}
+	if s.has(OutputDevDoc) && c.Doc != nil {
+		devdoc := c.Doc.DevDoc()
+		out.DevDoc = &devdoc
+	}
+	if c.IsInterface {
+		return out
+	}
+	if s.has(OutputBytecode) {
+		out.Bytecode = hex.EncodeToString(c.Artifacts["bytecode.rybc"])
+	}
+	if s.has(OutputInitBytecode) {
+		out.InitBytecode = hex.EncodeToString(c.Artifacts["init.rybc"])
+	}
+	if s.has(OutputRYC) {
+		out.RYC = strings.TrimRight(string(c.Artifacts["bytecode.ryc"]), "\n")
+	}
+	if s.has(OutputInitRYC) {
+		out.InitRYC = strings.TrimRight(string(c.Artifacts["init.ryc"]), "\n")
+	}
+	return out
+}
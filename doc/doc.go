@@ -0,0 +1,335 @@
+// Package doc extrae la documentación de los contratos a partir de sus
+// comentarios ///. Un comentario /// documenta la declaración que le sigue:
+// un contrato o interfaz, una función, el constructor, un storage o un error.
+//
+// Cada línea puede empezar por una etiqueta; el texto sin etiqueta del principio
+// es el @notice y las líneas sin etiqueta continúan la anterior:
+//
+//	/// Transfiere tokens a otra cuenta.
+//	/// @dev No comprueba que to sea un contrato.
+//	/// @param to Cuenta que recibe los tokens.
+//	/// @param amount Cantidad que se transfiere.
+//	/// @return Si la transferencia se hizo.
+//	pub func transfer(to: address, amount: uint64): bool { ... }
+//
+// @notice va a la documentación de usuario (userdoc.json); @dev, @param y
+// @return a la de desarrollador (devdoc.json). Las etiquetas se comprueban
+// contra la declaración: cada @param debe nombrar un parámetro o una clave
+// del storage, y @return solo se admite si la declaración devuelve un valor.
+package doc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/codegen"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// Version es la versión del formato de userdoc.json y devdoc.json.
+const Version = 1
+
+// Secciones de la documentación de un contrato.
+const (
+	SectionFunction = "function"
+	SectionStorage  = "storage"
+	SectionError    = "error"
+)
+
+// Contract es la documentación de un contrato o interfaz, incluidos los miembros
+// que hereda.
+type Contract struct {
+	Name        string
+	IsInterface bool
+	Notice      string
+	Details     string
+	Entries     []Entry // Miembros documentados, de la clase más base a la más derivada.
+}
+
+// Entry es la documentación de un miembro de un contrato.
+type Entry struct {
+	Section     string  // SectionFunction, SectionStorage o SectionError.
+	Key         string  // Firma canónica, p. ej. "transfer(address,uint64)", o "constructor".
+	Declaration string  // Declaración legible, p. ej. "transfer(to: address, amount: uint64): bool".
+	Public      bool    // Las entradas privadas solo van a la documentación de desarrollador.
+	Notice      string  // @notice
+	Details     string  // @dev
+	Params      []Param // @param, en el orden de la declaración.
+	Return      string  // @return
+}
+
+// Param es la descripción de un parámetro.
+type Param struct {
+	Name        string
+	Description string
+}
+
+// Error es un comentario /// que no corresponde a su declaración. Line y Column
+// empiezan en 1 y señalan la etiqueta.
+type Error struct {
+	Class  string // Clase del comentario.
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error devuelve el mensaje original, sin la posición.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap devuelve el error original.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Extract devuelve la documentación de cada clase del programa, por nombre. El
+// programa debe haberse analizado con lexer.WithComments; si no, las clases no
+// tienen documentación. Devuelve el primer comentario /// incorrecto como *Error.
+func Extract(program *ast.Program) (map[string]*Contract, error) {
+	classes := make(map[string]*ast.ClassStatement)
+	for _, stmt := range program.Statements {
+		if class, ok := stmt.(*ast.ClassStatement); ok {
+			classes[class.Name] = class
+		}
+	}
+
+	own := make(map[string]*Contract, len(classes))
+	for name, class := range classes {
+		contract, err := extractClass(program.Comments, class)
+		if err != nil {
+			return nil, err
+		}
+		own[name] = contract
+	}
+
+	contracts := make(map[string]*Contract, len(own))
+	for name := range own {
+		contracts[name] = inherit(name, classes, own)
+	}
+	return contracts, nil
+}
+
+// extractClass lee los comentarios de la clase y de los miembros que declara.
+func extractClass(comments map[ast.Node]*ast.CommentGroup, class *ast.ClassStatement) (*Contract, error) {
+	d, err := parse(class.Name, comments[class], false, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	contract := &Contract{Name: class.Name, IsInterface: class.IsInterface, Notice: d.notice, Details: d.details}
+
+	for _, stmt := range class.Body {
+		var entry Entry
+		var params []ast.Key
+		returns := false
+		switch n := stmt.(type) {
+		case *ast.FuncStatement:
+			returns = n.ReturnType.Type != "" && n.ReturnType.Type != "void"
+			params = n.Params
+			entry = Entry{
+				Section:     SectionFunction,
+				Key:         codegen.Signature(n.Name, keyTypes(n.Params)),
+				Declaration: declaration(n.Name, n.Params, n.ReturnType.Type),
+				Public:      n.Public || class.IsInterface,
+			}
+		case *ast.ConstructorStatement:
+			params = n.Params
+			entry = Entry{Section: SectionFunction, Key: "constructor", Declaration: declaration("constructor", n.Params, ""), Public: true}
+		case *ast.StorageDeclaration:
+			returns = true
+			params = n.Params
+			entry = Entry{
+				Section:     SectionStorage,
+				Key:         codegen.Signature(n.Name, keyTypes(n.Params)),
+				Declaration: declaration(n.Name, n.Params, n.Value.Type),
+				Public:      n.Public,
+			}
+		case *ast.ErrorStatement:
+			params = n.Params
+			entry = Entry{Section: SectionError, Key: codegen.Signature(n.Name, keyTypes(n.Params)), Declaration: declaration(n.Name, n.Params, ""), Public: true}
+		default:
+			continue // Los /// de otras declaraciones son comentarios normales.
+		}
+
+		group := comments[stmt]
+		if group == nil {
+			continue
+		}
+		d, err := parse(class.Name, group, true, params, returns)
+		if err != nil {
+			return nil, err
+		}
+		if d.empty() {
+			continue
+		}
+		entry.Notice, entry.Details, entry.Return = d.notice, d.details, d.returns
+		for _, param := range params {
+			if description, ok := d.params[param.Name]; ok {
+				entry.Params = append(entry.Params, Param{Name: param.Name, Description: *description})
+			}
+		}
+		contract.Entries = append(contract.Entries, entry)
+	}
+	return contract, nil
+}
+
+// inherit devuelve la documentación de name con la de sus bases delante. Un
+// miembro redefinido conserva la documentación de la clase más derivada que lo documenta.
+func inherit(name string, classes map[string]*ast.ClassStatement, own map[string]*Contract) *Contract {
+	contract := *own[name]
+	contract.Entries = nil
+
+	seen := make(map[string]bool)
+	var entries []Entry
+	var visit func(name string)
+	visit = func(name string) {
+		class, ok := classes[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		for _, parent := range class.Parents {
+			visit(parent)
+		}
+		for _, entry := range own[name].Entries {
+			if entry.Key == "constructor" && name != contract.Name {
+				continue // Los constructores no se heredan.
+			}
+			replaced := false
+			for i := range entries {
+				if entries[i].Section == entry.Section && entries[i].Key == entry.Key {
+					entries[i], replaced = entry, true
+				}
+			}
+			if !replaced {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	visit(name)
+	contract.Entries = entries
+	return &contract
+}
+
+// doc es el contenido de un bloque de comentarios ///.
+type doc struct {
+	notice  string
+	details string
+	params  map[string]*string
+	returns string
+}
+
+func (d doc) empty() bool {
+	return d.notice == "" && d.details == "" && len(d.params) == 0 && d.returns == ""
+}
+
+// parse lee los comentarios /// que preceden a una declaración. member indica si
+// la declaración es un miembro de la clase, con los parámetros params; returns
+// indica si devuelve un valor.
+func parse(class string, group *ast.CommentGroup, member bool, params []ast.Key, returns bool) (doc, error) {
+	d := doc{params: make(map[string]*string)}
+	if group == nil {
+		return d, nil
+	}
+	fail := func(c token.Token, offset int, format string, args ...interface{}) (doc, error) {
+		return doc{}, &Error{Class: class, Line: c.Line, Column: c.Column + offset, Err: fmt.Errorf(format, args...)}
+	}
+
+	var current *string // Texto al que se añaden las líneas sin etiqueta.
+	for _, c := range group.Leading {
+		if !strings.HasPrefix(c.Literal, "///") || strings.HasPrefix(c.Literal, "////") {
+			continue
+		}
+		body := c.Literal[len("///"):]
+		text := strings.TrimSpace(body)
+		offset := len("///") + len(body) - len(strings.TrimLeft(body, " \t")) // Columna de la etiqueta.
+
+		if !strings.HasPrefix(text, "@") {
+			if current == nil {
+				current = &d.notice
+			}
+			appendText(current, text)
+			continue
+		}
+
+		tag, rest, _ := strings.Cut(text, " ")
+		rest = strings.TrimSpace(rest)
+		switch tag {
+		case "@notice":
+			current = &d.notice
+		case "@dev":
+			current = &d.details
+		case "@param":
+			if !member {
+				return fail(c, offset, "doc: '@param' solo se admite en funciones, storages y errores")
+			}
+			name, description, _ := strings.Cut(rest, " ")
+			if name == "" {
+				return fail(c, offset, "doc: '@param' sin nombre de parámetro")
+			}
+			if !hasParam(params, name) {
+				return fail(c, offset, "doc: '@param %s' no corresponde a ningún parámetro", name)
+			}
+			if _, ok := d.params[name]; ok {
+				return fail(c, offset, "doc: el parámetro '%s' está documentado dos veces", name)
+			}
+			current = new(string)
+			d.params[name] = current
+			rest = strings.TrimSpace(description)
+		case "@return":
+			if !returns {
+				return fail(c, offset, "doc: '@return' en una declaración que no devuelve ningún valor")
+			}
+			if d.returns != "" {
+				return fail(c, offset, "doc: '@return' repetido; la declaración devuelve un solo valor")
+			}
+			current = &d.returns
+		default:
+			return fail(c, offset, "doc: etiqueta desconocida '%s'", tag)
+		}
+		appendText(current, rest)
+	}
+	return d, nil
+}
+
+// appendText añade una línea de texto a s, separada por un espacio.
+func appendText(s *string, text string) {
+	switch {
+	case text == "":
+	case *s == "":
+		*s = text
+	default:
+		*s += " " + text
+	}
+}
+
+func hasParam(params []ast.Key, name string) bool {
+	for _, param := range params {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func keyTypes(keys []ast.Key) []string {
+	types := make([]string, 0, len(keys))
+	for _, key := range keys {
+		types = append(types, key.Type)
+	}
+	return types
+}
+
+// declaration devuelve la declaración legible de un miembro, con nombres y tipos.
+func declaration(name string, params []ast.Key, returnType string) string {
+	list := make([]string, 0, len(params))
+	for _, param := range params {
+		list = append(list, param.Name+": "+param.Type)
+	}
+	out := name + "(" + strings.Join(list, ", ") + ")"
+	if returnType != "" && returnType != "void" {
+		out += ": " + returnType
+	}
+	return out
+}
@@ -0,0 +1,189 @@
+package doc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/parser"
+)
+
+const vault = `pragma: "1.0.0";
+
+/// Guarda saldos por cuenta.
+/// @dev El saldo no genera intereses.
+class contract Vault is Owned {
+
+    /// Saldo de cada cuenta.
+    /// @param account Cuenta consultada.
+    /// @return Saldo en unidades.
+    pub storage balance(account: address): uint64;
+
+    /// No hay saldo suficiente.
+    /// @param needed Cantidad pedida.
+    error InsufficientBalance(needed: uint64, available: uint64);
+
+    /// @param owner Primer propietario.
+    constructor(owner: address) {
+    }
+
+    /// Retira fondos de una cuenta.
+    /// @dev Revierte con InsufficientBalance si
+    /// el saldo no alcanza.
+    /// @param account Cuenta de la que se retira.
+    /// @param amount Cantidad que se retira.
+    pub func withdraw(account: address, amount: uint64): void {
+    }
+
+    /// @dev Solo para uso interno.
+    func fee(amount: uint64): uint64 {
+        return amount;
+    }
+
+    // Un comentario normal no es documentación.
+    pub func total(): uint64 {
+        return 0;
+    }
+}
+
+class contract Owned {
+
+    /// Propietario del contrato.
+    pub func owner(): address {
+        return self();
+    }
+
+    /// Retira fondos.
+    pub func withdraw(account: address, amount: uint64): void {
+    }
+}
+`
+
+func extract(t *testing.T, src string) (map[string]*Contract, error) {
+	t.Helper()
+	p := parser.New(lexer.New(src, lexer.WithComments()))
+	program := p.ParseProgram().(*ast.Program)
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("unexpected syntax errors: %v", errs)
+	}
+	return Extract(program)
+}
+
+func TestExtract(t *testing.T) {
+	contracts, err := extract(t, vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := contracts["Vault"]
+	if c.Notice != "Guarda saldos por cuenta." || c.Details != "El saldo no genera intereses." {
+		t.Fatalf("unexpected contract doc %q / %q", c.Notice, c.Details)
+	}
+
+	userdoc := c.UserDoc()
+	wantUser := UserDoc{
+		Kind:    "user",
+		Version: Version,
+		Notice:  "Guarda saldos por cuenta.",
+		Methods: map[string]UserEntry{
+			"owner()":                  {Notice: "Propietario del contrato."},
+			"withdraw(address,uint64)": {Notice: "Retira fondos de una cuenta."},
+		},
+		Storage: map[string]UserEntry{"balance(address)": {Notice: "Saldo de cada cuenta."}},
+		Errors:  map[string]UserEntry{"InsufficientBalance(uint64,uint64)": {Notice: "No hay saldo suficiente."}},
+	}
+	if !reflect.DeepEqual(userdoc, wantUser) {
+		t.Fatalf("unexpected userdoc:\n%+v\nwant:\n%+v", userdoc, wantUser)
+	}
+
+	devdoc := c.DevDoc()
+	wantDev := DevDoc{
+		Kind:    "dev",
+		Version: Version,
+		Details: "El saldo no genera intereses.",
+		Methods: map[string]DevEntry{
+			"constructor": {Params: map[string]string{"owner": "Primer propietario."}},
+			"withdraw(address,uint64)": {
+				Details: "Revierte con InsufficientBalance si el saldo no alcanza.",
+				Params:  map[string]string{"account": "Cuenta de la que se retira.", "amount": "Cantidad que se retira."},
+			},
+			"fee(uint64)": {Details: "Solo para uso interno."},
+		},
+		Storage: map[string]DevEntry{"balance(address)": {
+			Params:  map[string]string{"account": "Cuenta consultada."},
+			Returns: map[string]string{"_0": "Saldo en unidades."},
+		}},
+		Errors: map[string]DevEntry{"InsufficientBalance(uint64,uint64)": {Params: map[string]string{"needed": "Cantidad pedida."}}},
+	}
+	if !reflect.DeepEqual(devdoc, wantDev) {
+		t.Fatalf("unexpected devdoc:\n%+v\nwant:\n%+v", devdoc, wantDev)
+	}
+
+	md := string(c.Markdown())
+	for _, want := range []string{
+		"# Vault\n\nGuarda saldos por cuenta.\n",
+		"## Funciones\n\n### `owner(): address`\n\nPropietario del contrato.\n",
+		"### `withdraw(account: address, amount: uint64)`\n",
+		"| `amount` | Cantidad que se retira. |\n",
+		"## Storage\n\n### `balance(account: address): uint64`\n",
+		"**Devuelve:** Saldo en unidades.\n",
+		"## Errores\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected the Markdown reference to contain %q, got:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "fee(") {
+		t.Errorf("private functions must not appear in the Markdown reference:\n%s", md)
+	}
+}
+
+func TestExtractWithoutComments(t *testing.T) {
+	p := parser.New(lexer.New(vault))
+	program := p.ParseProgram().(*ast.Program)
+	contracts, err := Extract(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := contracts["Vault"]; c.Notice != "" || len(c.Entries) != 0 {
+		t.Fatalf("expected no documentation without lexer.WithComments, got %+v", c)
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		decl string
+		want string
+		col  int
+	}{
+		{"unknown param", "/// @param amount Cantidad.", "pub func f(value: uint64): void {}", "doc: '@param amount' no corresponde a ningún parámetro", 9},
+		{"duplicate param", "/// @param a Uno.\n    /// @param a Otro.", "pub func f(a: uint64): void {}", "doc: el parámetro 'a' está documentado dos veces", 9},
+		{"missing name", "///   @param", "error E(a: uint64);", "doc: '@param' sin nombre de parámetro", 11},
+		{"return on void", "/// @return Nada.", "pub func f(): void {}", "doc: '@return' en una declaración que no devuelve ningún valor", 9},
+		{"return on error", "/// @return Nada.", "error E(a: uint64);", "doc: '@return' en una declaración que no devuelve ningún valor", 9},
+		{"repeated return", "/// @return Uno.\n    /// @return Dos.", "pub func f(): uint64 { return 1; }", "doc: '@return' repetido; la declaración devuelve un solo valor", 9},
+		{"unknown tag", "/// @author Alguien", "pub func f(): void {}", "doc: etiqueta desconocida '@author'", 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "pragma: \"1.0.0\";\nclass contract A {\n    " + tt.doc + "\n    " + tt.decl + "\n}\n"
+			_, err := extract(t, src)
+			var docErr *Error
+			if !errors.As(err, &docErr) {
+				t.Fatalf("expected a *doc.Error, got %v", err)
+			}
+			if docErr.Error() != tt.want || docErr.Class != "A" || docErr.Line != 3+strings.Count(tt.doc, "\n") || docErr.Column != tt.col {
+				t.Fatalf("unexpected error %s at %d:%d", docErr, docErr.Line, docErr.Column)
+			}
+		})
+	}
+
+	_, err := extract(t, "pragma: \"1.0.0\";\n/// @param x Nada.\nclass contract A {}\n")
+	if err == nil || err.Error() != "doc: '@param' solo se admite en funciones, storages y errores" {
+		t.Fatalf("expected @param on a contract to fail, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// UserDoc es la documentación para quien usa el contrato: los @notice del
+// contrato y de sus miembros públicos, por firma.
+type UserDoc struct {
+	Kind    string               `json:"kind"` // Siempre "user".
+	Version int                  `json:"version"`
+	Notice  string               `json:"notice,omitempty"`
+	Methods map[string]UserEntry `json:"methods"`
+	Storage map[string]UserEntry `json:"storage,omitempty"`
+	Errors  map[string]UserEntry `json:"errors,omitempty"`
+}
+
+// UserEntry es la documentación de usuario de un miembro.
+type UserEntry struct {
+	Notice string `json:"notice"`
+}
+
+// DevDoc es la documentación para quien desarrolla o audita el contrato: los
+// @dev, @param y @return de todos sus miembros, por firma.
+type DevDoc struct {
+	Kind    string              `json:"kind"` // Siempre "dev".
+	Version int                 `json:"version"`
+	Details string              `json:"details,omitempty"`
+	Methods map[string]DevEntry `json:"methods"`
+	Storage map[string]DevEntry `json:"storage,omitempty"`
+	Errors  map[string]DevEntry `json:"errors,omitempty"`
+}
+
+// DevEntry es la documentación de desarrollador de un miembro. El valor devuelto
+// se llama "_0", como el primer valor sin nombre en la ABI.
+type DevEntry struct {
+	Details string            `json:"details,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
+	Returns map[string]string `json:"returns,omitempty"`
+}
+
+// UserDoc devuelve la documentación de usuario del contrato.
+func (c *Contract) UserDoc() UserDoc {
+	out := UserDoc{Kind: "user", Version: Version, Notice: c.Notice, Methods: make(map[string]UserEntry)}
+	for _, entry := range c.Entries {
+		if !entry.Public || entry.Notice == "" {
+			continue
+		}
+		section := &out.Methods
+		switch entry.Section {
+		case SectionStorage:
+			section = &out.Storage
+		case SectionError:
+			section = &out.Errors
+		}
+		if *section == nil {
+			*section = make(map[string]UserEntry)
+		}
+		(*section)[entry.Key] = UserEntry{Notice: entry.Notice}
+	}
+	return out
+}
+
+// DevDoc devuelve la documentación de desarrollador del contrato.
+func (c *Contract) DevDoc() DevDoc {
+	out := DevDoc{Kind: "dev", Version: Version, Details: c.Details, Methods: make(map[string]DevEntry)}
+	for _, entry := range c.Entries {
+		dev := DevEntry{Details: entry.Details}
+		for _, param := range entry.Params {
+			if dev.Params == nil {
+				dev.Params = make(map[string]string)
+			}
+			dev.Params[param.Name] = param.Description
+		}
+		if entry.Return != "" {
+			dev.Returns = map[string]string{"_0": entry.Return}
+		}
+		if dev.Details == "" && dev.Params == nil && dev.Returns == nil {
+			continue
+		}
+		section := &out.Methods
+		switch entry.Section {
+		case SectionStorage:
+			section = &out.Storage
+		case SectionError:
+			section = &out.Errors
+		}
+		if *section == nil {
+			*section = make(map[string]DevEntry)
+		}
+		(*section)[entry.Key] = dev
+	}
+	return out
+}
+
+// Markdown devuelve la referencia del contrato en Markdown: su descripción y la
+// de cada miembro público documentado, en orden de declaración.
+func (c *Contract) Markdown() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", c.Name)
+	paragraph(&buf, c.Notice)
+	paragraph(&buf, c.Details)
+
+	sections := []struct{ name, title string }{
+		{SectionFunction, "Funciones"},
+		{SectionStorage, "Storage"},
+		{SectionError, "Errores"},
+	}
+	for _, section := range sections {
+		title := false
+		for _, entry := range c.Entries {
+			if entry.Section != section.name || !entry.Public {
+				continue
+			}
+			if !title {
+				fmt.Fprintf(&buf, "\n## %s\n", section.title)
+				title = true
+			}
+			fmt.Fprintf(&buf, "\n### `%s`\n", entry.Declaration)
+			paragraph(&buf, entry.Notice)
+			paragraph(&buf, entry.Details)
+			if len(entry.Params) > 0 {
+				buf.WriteString("\n| Parámetro | Descripción |\n| --- | --- |\n")
+				for _, param := range entry.Params {
+					fmt.Fprintf(&buf, "| `%s` | %s |\n", param.Name, strings.ReplaceAll(param.Description, "|", "\\|"))
+				}
+			}
+			if entry.Return != "" {
+				fmt.Fprintf(&buf, "\n**Devuelve:** %s\n", entry.Return)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// paragraph escribe text como un párrafo, si no está vacío.
+func paragraph(buf *bytes.Buffer, text string) {
+	if text != "" {
+		fmt.Fprintf(buf, "\n%s\n", text)
+	}
+}
@@ -0,0 +1,263 @@
+// Package flow construye el grafo de flujo de control de cada función Ryot y
+// busca en él dos problemas:
+//
+//   - Funciones con tipo de retorno que pueden llegar al final del cuerpo sin
+//     return. El compilador las rechaza.
+//   - Sentencias que nunca se ejecutan porque van después de un return, de un
+//     revert o de un check cuya condición siempre es falsa. El compilador avisa
+//     de ellas.
+//
+// Un bloque del grafo es una secuencia de sentencias que se ejecutan seguidas.
+// return salta a la salida normal, revert a la salida con error y check se
+// bifurca: sigue si la condición se cumple y revierte si no.
+package flow
+
+import (
+	"fmt"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// Block es un bloque básico del grafo.
+type Block struct {
+	Index      int             // Posición en Graph.Blocks.
+	Statements []ast.Statement // Sentencias del bloque, en orden.
+	Succs      []*Block        // Bloques que pueden ejecutarse a continuación.
+}
+
+// Graph es el grafo de flujo de control del cuerpo de una función, un
+// constructor o un modificador.
+type Graph struct {
+	Blocks []*Block
+	Entry  *Block // Primer bloque del cuerpo.
+	End    *Block // Final del cuerpo sin return; su único sucesor es Return.
+	Return *Block // Salida normal.
+	Revert *Block // Salida por revert o por un check que falla.
+
+	reachable map[*Block]bool
+	blockOf   map[ast.Statement]*Block
+}
+
+// Build construye el grafo de body.
+func Build(body []ast.Statement) *Graph {
+	g := &Graph{blockOf: make(map[ast.Statement]*Block)}
+	g.Return = g.newBlock()
+	g.Revert = g.newBlock()
+	g.End = g.newBlock()
+	g.End.Succs = []*Block{g.Return}
+	g.Entry = g.newBlock()
+
+	current := g.Entry
+	for _, stmt := range body {
+		current.Statements = append(current.Statements, stmt)
+		g.blockOf[stmt] = current
+
+		switch n := stmt.(type) {
+		case *ast.ReturnStatement:
+			current.Succs = append(current.Succs, g.Return)
+			current = g.newBlock() // Lo que sigue no tiene predecesores.
+		case *ast.RevertStatement:
+			current.Succs = append(current.Succs, g.Revert)
+			current = g.newBlock()
+		case *ast.ExpressionStatement:
+			check, ok := n.Expression.(*ast.ErrLiteral)
+			if !ok {
+				continue
+			}
+			value, constant := Constant(check.Value)
+			if !constant || !value {
+				current.Succs = append(current.Succs, g.Revert)
+			}
+			next := g.newBlock()
+			if !constant || value {
+				current.Succs = append(current.Succs, next)
+			}
+			current = next
+		}
+	}
+	current.Succs = append(current.Succs, g.End)
+
+	g.reachable = make(map[*Block]bool)
+	var visit func(b *Block)
+	visit = func(b *Block) {
+		if g.reachable[b] {
+			return
+		}
+		g.reachable[b] = true
+		for _, succ := range b.Succs {
+			visit(succ)
+		}
+	}
+	visit(g.Entry)
+	return g
+}
+
+func (g *Graph) newBlock() *Block {
+	b := &Block{Index: len(g.Blocks)}
+	g.Blocks = append(g.Blocks, b)
+	return b
+}
+
+// Reachable indica si b puede ejecutarse desde la entrada.
+func (g *Graph) Reachable(b *Block) bool {
+	return g.reachable[b]
+}
+
+// FallsOff indica si la ejecución puede llegar al final del cuerpo sin return.
+func (g *Graph) FallsOff() bool {
+	return g.reachable[g.End]
+}
+
+// Unreachable devuelve la primera sentencia de cada tramo de body que nunca se
+// ejecuta, junto con la sentencia que corta el flujo justo antes.
+func (g *Graph) Unreachable(body []ast.Statement) []Dead {
+	var dead []Dead
+	for i, stmt := range body {
+		if g.reachable[g.blockOf[stmt]] {
+			continue
+		}
+		if i > 0 && g.reachable[g.blockOf[body[i-1]]] {
+			dead = append(dead, Dead{Statement: stmt, After: body[i-1]})
+		}
+	}
+	return dead
+}
+
+// Dead es una sentencia que nunca se ejecuta.
+type Dead struct {
+	Statement ast.Statement // Primera sentencia inalcanzable.
+	After     ast.Statement // return, revert o check que corta el flujo.
+}
+
+// Constant evalúa una condición que no depende de la ejecución: un literal
+// booleano, una comparación entre enteros literales o una combinación con &&
+// y || de ellas. ok es false si la condición no es constante.
+func Constant(expr ast.Expression) (value bool, ok bool) {
+	switch n := expr.(type) {
+	case *ast.BooleanLiteral:
+		return n.Value, true
+	case *ast.BinaryExpression:
+		if n.Operator == "&&" || n.Operator == "||" {
+			left, lok := Constant(n.Left)
+			right, rok := Constant(n.Right)
+			switch {
+			case lok && rok && n.Operator == "&&":
+				return left && right, true
+			case lok && rok:
+				return left || right, true
+			case n.Operator == "&&" && ((lok && !left) || (rok && !right)):
+				return false, true
+			case n.Operator == "||" && ((lok && left) || (rok && right)):
+				return true, true
+			}
+			return false, false
+		}
+		left, lok := n.Left.(*ast.IntegerLiteral)
+		right, rok := n.Right.(*ast.IntegerLiteral)
+		if !lok || !rok {
+			return false, false
+		}
+		switch n.Operator {
+		case "==":
+			return left.Value == right.Value, true
+		case "!=":
+			return left.Value != right.Value, true
+		case "<":
+			return left.Value < right.Value, true
+		case ">":
+			return left.Value > right.Value, true
+		case "<=":
+			return left.Value <= right.Value, true
+		case ">=":
+			return left.Value >= right.Value, true
+		}
+	}
+	return false, false
+}
+
+// Tipos de problema.
+const (
+	MissingReturn = "missing-return"   // Error: la función puede terminar sin return.
+	Unreachable   = "unreachable-code" // Aviso: la sentencia nunca se ejecuta.
+)
+
+// Issue es un problema de flujo de control en una clase.
+type Issue struct {
+	Kind    string // MissingReturn o Unreachable.
+	Class   string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error devuelve el mensaje sin la posición.
+func (i Issue) Error() string {
+	return "flow: " + i.Message
+}
+
+// Check analiza las funciones, constructores y modificadores de las clases del
+// programa y devuelve sus problemas en orden de aparición.
+func Check(program *ast.Program) []Issue {
+	var issues []Issue
+	for _, stmt := range program.Statements {
+		class, ok := stmt.(*ast.ClassStatement)
+		if !ok || class.IsInterface {
+			continue
+		}
+		for _, member := range class.Body {
+			issues = append(issues, checkMember(class.Name, member)...)
+		}
+	}
+	return issues
+}
+
+// checkMember analiza una función, un constructor o un modificador.
+func checkMember(class string, member ast.Statement) []Issue {
+	var body []ast.Statement
+	switch m := member.(type) {
+	case *ast.FuncStatement:
+		body = m.Body
+	case *ast.ConstructorStatement:
+		body = m.Body
+	case *ast.ModifierStatement:
+		body = m.Body
+	default:
+		return nil
+	}
+	if body == nil {
+		return nil // Función sin cuerpo, como las de una interfaz.
+	}
+
+	g := Build(body)
+	var issues []Issue
+	if fn, ok := member.(*ast.FuncStatement); ok && returnsValue(fn) && g.FallsOff() {
+		issues = append(issues, issue(MissingReturn, class, fn.Token,
+			"la función '%s' devuelve %s pero puede terminar sin return", fn.Name, fn.ReturnType.Type))
+	}
+	for _, dead := range g.Unreachable(body) {
+		tok, _ := ast.TokenOf(dead.Statement)
+		issues = append(issues, issue(Unreachable, class, tok, "código inalcanzable después de %s", describe(dead.After)))
+	}
+	return issues
+}
+
+func issue(kind, class string, tok token.Token, format string, args ...interface{}) Issue {
+	return Issue{Kind: kind, Class: class, Line: tok.Line, Column: tok.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+// returnsValue indica si fn declara un tipo de retorno distinto de void.
+func returnsValue(fn *ast.FuncStatement) bool {
+	return fn.ReturnType.Type != "" && fn.ReturnType.Type != "void"
+}
+
+// describe nombra la sentencia que corta el flujo en los mensajes.
+func describe(stmt ast.Statement) string {
+	switch stmt.(type) {
+	case *ast.ReturnStatement:
+		return "return"
+	case *ast.RevertStatement:
+		return "revert"
+	}
+	return "un check que siempre falla"
+}
@@ -0,0 +1,145 @@
+package flow
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/parser"
+)
+
+func parse(t *testing.T, src string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram().(*ast.Program)
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse errors: %v", errs)
+	}
+	return program
+}
+
+// body devuelve el cuerpo de la primera función de la primera clase de src.
+func body(t *testing.T, src string) []ast.Statement {
+	t.Helper()
+	program := parse(t, "pragma: \"1.0.0\";\nclass contract A {\n"+src+"\n}\n")
+	return program.Statements[1].(*ast.ClassStatement).Body[0].(*ast.FuncStatement).Body
+}
+
+func TestBuild(t *testing.T) {
+	stmts := body(t, `pub func f(a: uint64): uint64 {
+        uint64 b: a;
+        check(a > 0, err: "zero");
+        return b;
+    }`)
+	g := Build(stmts)
+
+	if len(g.Entry.Statements) != 2 || len(g.Entry.Succs) != 2 || g.Entry.Succs[0] != g.Revert {
+		t.Fatalf("expected the check to branch to revert and to the next block, got %+v", g.Entry)
+	}
+	next := g.Entry.Succs[1]
+	if len(next.Statements) != 1 || !reflect.DeepEqual(next.Succs, []*Block{g.Return}) {
+		t.Fatalf("expected the return block to go to the exit, got %+v", next)
+	}
+	if g.FallsOff() || !g.Reachable(g.Revert) || !g.Reachable(g.Return) {
+		t.Fatalf("unexpected reachability: falls off %v", g.FallsOff())
+	}
+	for i, b := range g.Blocks {
+		if b.Index != i {
+			t.Fatalf("block %d has index %d", i, b.Index)
+		}
+	}
+}
+
+func TestBuildFallsOff(t *testing.T) {
+	tests := []struct {
+		src      string
+		fallsOff bool
+		dead     int
+	}{
+		{`pub func f(): uint64 { }`, true, 0},
+		{`pub func f(a: uint64): uint64 { check(a > 0, err: "zero"); }`, true, 0},
+		{`pub func f(): uint64 { revert Missing(); }`, false, 0},
+		{`pub func f(): uint64 { check(false, err: "never"); }`, false, 0},
+		{`pub func f(): uint64 { check(false || 2 < 1, err: "never"); return 1; }`, false, 1},
+		{`pub func f(): uint64 { check(1 < 2, err: "always"); return 1; }`, false, 0},
+		{`pub func f(): uint64 { return 1; return 2; return 3; }`, false, 1},
+	}
+	for _, tt := range tests {
+		stmts := body(t, tt.src)
+		g := Build(stmts)
+		if g.FallsOff() != tt.fallsOff {
+			t.Errorf("%s: expected falls off %v", tt.src, tt.fallsOff)
+		}
+		if dead := g.Unreachable(stmts); len(dead) != tt.dead {
+			t.Errorf("%s: expected %d unreachable statements, got %d", tt.src, tt.dead, len(dead))
+		}
+	}
+}
+
+func TestConstant(t *testing.T) {
+	tests := []struct {
+		cond  string
+		value bool
+		ok    bool
+	}{
+		{"true", true, true},
+		{"false", false, true},
+		{"1 == 1", true, true},
+		{"3 <= 2", false, true},
+		{"false && a > 0", false, true},
+		{"true || a > 0", true, true},
+		{"true && a > 0", false, false},
+		{"a > 0", false, false},
+	}
+	for _, tt := range tests {
+		stmts := body(t, `pub func f(a: uint64): void { check(`+tt.cond+`, err: "x"); }`)
+		cond := stmts[0].(*ast.ExpressionStatement).Expression.(*ast.ErrLiteral).Value
+		if value, ok := Constant(cond); value != tt.value || ok != tt.ok {
+			t.Errorf("%s: expected (%v, %v), got (%v, %v)", tt.cond, tt.value, tt.ok, value, ok)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	program := parse(t, `pragma: "1.0.0";
+
+class interface I {
+    pub func g(): uint64;
+}
+
+class contract A {
+
+    constructor() {
+        return;
+        check(true, err: "x");
+    }
+
+    modifier never() {
+        check(false, err: "never");
+        _;
+    }
+
+    pub func f(a: uint64): uint64 {
+        check(a > 0, err: "zero");
+    }
+
+    pub func h(): void {
+        revert Missing();
+        uint64 x: 1;
+    }
+}
+`)
+	want := []Issue{
+		{Unreachable, "A", 11, 9, "código inalcanzable después de return"},
+		{Unreachable, "A", 16, 9, "código inalcanzable después de un check que siempre falla"},
+		{MissingReturn, "A", 19, 9, "la función 'f' devuelve uint64 pero puede terminar sin return"},
+		{Unreachable, "A", 25, 9, "código inalcanzable después de revert"},
+	}
+	if got := Check(program); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected issues:\n got %+v\nwant %+v", got, want)
+	}
+	if got := want[2].Error(); got != "flow: la función 'f' devuelve uint64 pero puede terminar sin return" {
+		t.Fatalf("unexpected error %q", got)
+	}
+}
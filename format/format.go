@@ -0,0 +1,181 @@
+// Package format imprime programas Ryot en su forma canónica: cuatro espacios de
+// sangría, una declaración por línea, como mucho una línea en blanco seguida y
+// los comentarios // y /* */ junto a las declaraciones a las que acompañan.
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/parser"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// Source formatea el código fuente src conservando sus comentarios. Devuelve un
+// error si src tiene errores de sintaxis o si el formateo perdería código que el
+// parser acepta pero no guarda en el AST.
+func Source(src []byte) ([]byte, error) {
+	p := parser.New(lexer.New(string(src)))
+	program, ok := p.ParseProgram().(*ast.Program)
+	if !ok {
+		return nil, fmt.Errorf("format: el parser no devolvió un programa")
+	}
+	if errs := p.SyntaxErrors(); len(errs) > 0 {
+		return nil, fmt.Errorf("format: %v", errs[0])
+	}
+
+	tokens, comments := scan(src)
+	pr := newPrinter(tokens, comments)
+	pr.program(program)
+	out := pr.bytes()
+
+	if err := verify(tokens, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Program imprime un programa en su forma canónica. Como el AST no guarda los
+// comentarios ni las líneas en blanco, la salida no los tiene.
+func Program(program *ast.Program) []byte {
+	pr := newPrinter(nil, nil)
+	pr.program(program)
+	return pr.bytes()
+}
+
+// source es el código original ya dividido en tokens, para situar en la salida
+// los comentarios, las líneas en blanco y las llaves de cierre.
+type source struct {
+	tokens   []token.Token // Tokens del código, sin comentarios ni EOF.
+	comments []token.Token // Todos los comentarios, en orden.
+	closing  map[int]int   // Índice de la llave de cierre de cada llave de apertura.
+	lines    map[int]bool  // Líneas con algún token.
+}
+
+// scan divide src en tokens y comentarios.
+func scan(src []byte) ([]token.Token, []token.Token) {
+	l := lexer.New(string(src), lexer.WithComments())
+	var tokens, comments []token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type == token.COMMENT {
+			comments = append(comments, tok)
+		} else {
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens, comments
+}
+
+func newSource(tokens, comments []token.Token) *source {
+	s := &source{
+		tokens:   tokens,
+		comments: comments,
+		closing:  make(map[int]int),
+		lines:    make(map[int]bool),
+	}
+	var open []int
+	for i, tok := range tokens {
+		s.lines[tok.Line] = true
+		switch tok.Type {
+		case token.LBRACE:
+			open = append(open, i)
+		case token.RBRACE:
+			if len(open) > 0 {
+				s.closing[open[len(open)-1]] = i
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return s
+}
+
+// before indica si a está antes que b en el código.
+func before(a, b token.Token) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
+
+// index devuelve el índice del primer token que no está antes de pos.
+func (s *source) index(pos token.Token) int {
+	return sort.Search(len(s.tokens), func(i int) bool { return !before(s.tokens[i], pos) })
+}
+
+// start devuelve el primer token de la declaración que empieza en pos, incluidos
+// su pub o priv y su const o immutable.
+func (s *source) start(pos token.Token) token.Token {
+	i := s.index(pos)
+	for i > 0 && (s.tokens[i-1].Type == token.PUB || s.tokens[i-1].Type == token.PRIV || s.tokens[i-1].Is(token.CONST) || s.tokens[i-1].Is(token.IMMUTABLE)) {
+		i--
+	}
+	if i < len(s.tokens) {
+		return s.tokens[i]
+	}
+	return pos
+}
+
+// prevLine devuelve la línea del último token o comentario anterior a pos.
+func (s *source) prevLine(pos token.Token) int {
+	line := 0
+	if i := s.index(pos); i > 0 {
+		line = s.tokens[i-1].Line
+	}
+	for _, c := range s.comments {
+		if !before(c, pos) {
+			break
+		}
+		if end := c.Line + strings.Count(c.Literal, "\n"); end > line {
+			line = end // Un comentario /* */ puede ocupar varias líneas.
+		}
+	}
+	return line
+}
+
+// closingBrace devuelve la llave que cierra el primer bloque abierto tras pos.
+func (s *source) closingBrace(pos token.Token) (token.Token, bool) {
+	for i := s.index(pos); i < len(s.tokens); i++ {
+		if s.tokens[i].Type == token.LBRACE {
+			if j, ok := s.closing[i]; ok {
+				return s.tokens[j], true
+			}
+			return token.Token{}, false
+		}
+	}
+	return token.Token{}, false
+}
+
+// trailing indica si el comentario está en una línea con código, tras él.
+func (s *source) trailing(comment token.Token) bool {
+	return s.lines[comment.Line]
+}
+
+// verify comprueba que la salida tenga los mismos tokens que el código original,
+// salvo los que no cambian el programa: paréntesis, punto y coma y priv.
+func verify(original []token.Token, formatted []byte) error {
+	want := significant(original)
+	tokens, _ := scan(formatted)
+	got := significant(tokens)
+	for i, tok := range want {
+		if i >= len(got) || got[i].Type != tok.Type || got[i].Literal != tok.Literal {
+			return fmt.Errorf("format: %d:%d: el formateo perdería '%s'; el parser no lo guarda en el AST", tok.Line, tok.Column, tok.Literal)
+		}
+	}
+	if len(got) > len(want) {
+		tok := got[len(want)]
+		return fmt.Errorf("format: la salida añade '%s' que no está en el código original", tok.Literal)
+	}
+	return nil
+}
+
+func significant(tokens []token.Token) []token.Token {
+	var out []token.Token
+	for _, tok := range tokens {
+		switch tok.Type {
+		case token.SEMICOLON, token.LPAREN, token.RPAREN, token.PRIV:
+			continue
+		}
+		out = append(out, tok)
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/compiler"
+	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/parser"
+)
+
+func TestSourceComments(t *testing.T) {
+	src := `// License header
+
+pragma: "1.0.0"; // version
+import "./a.ry";
+import { B } from "./b.ry";
+// about the class
+class contract A is B { // trailing open
+	// first member doc
+  pub storage owners(account: address): bool;   // trailing storage
+
+
+  pub func f(a: uint64, b: uint64): uint64 {
+      // lead
+      return (a + b) * b; // ret
+      // end of body
+  } // after close
+  // end of class
+}
+// eof comment
+`
+	want := `// License header
+
+pragma: "1.0.0"; // version
+
+import "./a.ry";
+import { B } from "./b.ry";
+
+// about the class
+class contract A is B { // trailing open
+    // first member doc
+    pub storage owners(account: address): bool; // trailing storage
+
+    pub func f(a: uint64, b: uint64): uint64 {
+        // lead
+        return (a + b) * b; // ret
+        // end of body
+    } // after close
+    // end of class
+}
+// eof comment
+`
+	out, err := Source([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+
+	again, err := Source(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(out) {
+		t.Fatalf("formatting is not idempotent:\n%s", again)
+	}
+}
+
+func TestSourceBlockComments(t *testing.T) {
+	src := `/* Counter
+ * keeps a total */
+class contract A {
+  uint64 total: 0; /* running */
+  pub func f(): uint64 {
+    /* lead */ return total;
+  }
+}
+`
+	want := `/* Counter
+ * keeps a total */
+class contract A {
+    uint64 total: 0; /* running */
+    pub func f(): uint64 {
+        /* lead */
+        return total;
+    }
+}
+`
+	out, err := Source([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// Formatear los ejemplos no debe cambiar el bytecode que generan.
+func TestSourceExamples(t *testing.T) {
+	files, err := filepath.Glob("../example/*.ry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	imports, _ := filepath.Glob("../example/imports/*.ry")
+	files = append(files, imports...)
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Source(src)
+		if filepath.Base(file) == "storage.ry" {
+			// count(id)++ se acepta pero el AST pierde el ++: no se puede formatear.
+			if err == nil || !strings.Contains(err.Error(), "perdería '+'") {
+				t.Fatalf("%s: expected the formatter to refuse lossy code, got %v", file, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if string(out) != string(src) {
+			t.Fatalf("%s is not formatted, run ryotc fmt -w", file)
+		}
+
+		original := parse(t, string(src))
+		formatted := parse(t, string(out))
+		if string(Program(original)) != string(Program(formatted)) {
+			t.Fatalf("%s: formatting changed the program", file)
+		}
+
+		opts := compiler.Options{InMemory: true, Filename: file}
+		before, err := compiler.CompileWithOptions(string(src), opts)
+		if err != nil {
+			continue // Algunos ejemplos solo se parsean.
+		}
+		after, err := compiler.CompileWithOptions(string(out), opts)
+		if err != nil {
+			t.Fatalf("%s: the formatted code does not compile: %v", file, err)
+		}
+		for name, contract := range before {
+			if !reflect.DeepEqual(contract.Bytecode, after[name].Bytecode) || !reflect.DeepEqual(contract.InitBytecode, after[name].InitBytecode) {
+				t.Fatalf("%s: formatting changed the bytecode of %s", file, name)
+			}
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	tests := map[string]string{
+		"syntax error": `pragma: "1.0.0"; class contract A { pub func f(a uint64): uint64 { return a; } }`,
+		"lossy":        `pragma: "1.0.0"; class contract A { pub func f(): void { count(1)++; } }`,
+	}
+	for name, src := range tests {
+		if _, err := Source([]byte(src)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestProgram(t *testing.T) {
+	program := parse(t, `pragma: "1.0.0";
+	class interface IToken { pub func transfer(to: address, amount: uint64): bool; }
+	class contract Token is IToken {
+		error Low(needed: uint64);
+		pub storage balances(account: address): uint64;
+		modifier positive(amount: uint64) { check(amount > 0, err: Low(amount)); _; }
+		pub func transfer(to: address, amount: uint64) positive(amount) override: bool {
+			balances(to): balances(to) + amount;
+			return true;
+		}
+		pub func balance(account: address)   view : uint64 { return balances(account); }
+	}`)
+
+	want := `pragma: "1.0.0";
+
+class interface IToken {
+    pub func transfer(to: address, amount: uint64): bool;
+}
+
+class contract Token is IToken {
+    error Low(needed: uint64);
+    pub storage balances(account: address): uint64;
+    modifier positive(amount: uint64) {
+        check(amount > 0, err: Low(amount));
+        _;
+    }
+    pub func transfer(to: address, amount: uint64) positive(amount) override: bool {
+        balances(to): balances(to) + amount;
+        return true;
+    }
+    pub func balance(account: address) view: uint64 {
+        return balances(account);
+    }
+}
+`
+	if got := string(Program(program)); got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func parse(t *testing.T, src string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) > 0 {
+		t.Fatalf("unexpected syntax errors: %v", p.Errors())
+	}
+	return program
+}
@@ -0,0 +1,374 @@
+package format
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+const indent = "    "
+
+// printer escribe el programa línea a línea. Cada línea lleva la línea del código
+// original que representa, para añadirle los comentarios que había al final de esa línea.
+type printer struct {
+	lines      []outputLine
+	depth      int
+	src        *source
+	pending    []token.Token // Comentarios que aún no se han escrito.
+	trailing   []token.Token // Comentarios que van al final de una línea ya escrita.
+	written    map[int]bool  // Líneas del código original que ya tienen una línea en la salida.
+	blockStart bool          // La última línea abrió un bloque o no hay ninguna.
+}
+
+// outputLine es una línea de la salida y la línea del código original que representa.
+type outputLine struct {
+	text    string
+	srcLine int
+}
+
+func newPrinter(tokens, comments []token.Token) *printer {
+	return &printer{
+		src:        newSource(tokens, comments),
+		pending:    comments,
+		written:    make(map[int]bool),
+		blockStart: true,
+	}
+}
+
+// bytes devuelve la salida. Cada comentario de final de línea se añade a la última
+// línea de la salida que representa su línea del código original.
+func (p *printer) bytes() []byte {
+	comments := make(map[int][]string)
+	for _, c := range p.trailing {
+		comments[c.Line] = append(comments[c.Line], c.Literal)
+	}
+	last := make(map[int]int)
+	for i, l := range p.lines {
+		if l.srcLine > 0 {
+			last[l.srcLine] = i
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, l := range p.lines {
+		buf.WriteString(l.text)
+		if l.srcLine > 0 && last[l.srcLine] == i {
+			for _, c := range comments[l.srcLine] {
+				buf.WriteString(" " + c)
+			}
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// line escribe una línea con la sangría actual. srcLine es la línea del código
+// original que representa, o cero.
+func (p *printer) line(srcLine int, text string) {
+	p.lines = append(p.lines, outputLine{text: strings.Repeat(indent, p.depth) + text, srcLine: srcLine})
+	p.written[srcLine] = true
+	p.blockStart = strings.HasSuffix(text, "{")
+}
+
+// separate escribe una línea en blanco antes de pos si la había en el código
+// original, o si force es true, salvo al principio de un bloque.
+func (p *printer) separate(pos token.Token, force bool) {
+	if p.blockStart {
+		return
+	}
+	preserved := len(p.src.tokens) > 0 && pos.Line > 0 && pos.Line > p.src.prevLine(pos)+1
+	if force || preserved {
+		p.lines = append(p.lines, outputLine{})
+	}
+}
+
+// comments escribe los comentarios pendientes anteriores a pos. Los que estaban
+// al final de una línea ya escrita se quedan con ella; el resto ocupan su propia
+// línea. Devuelve si escribió alguno en su propia línea.
+func (p *printer) comments(pos token.Token, force bool) bool {
+	written := false
+	for len(p.pending) > 0 && (pos.Line == 0 || before(p.pending[0], pos)) {
+		c := p.pending[0]
+		p.pending = p.pending[1:]
+		if p.src.trailing(c) && p.written[c.Line] {
+			p.trailing = append(p.trailing, c)
+			continue
+		}
+		p.separate(c, force && !written)
+		p.line(0, c.Literal)
+		written = true
+	}
+	return written
+}
+
+// begin prepara la escritura de la declaración que empieza en pos: escribe los
+// comentarios que la preceden y la línea en blanco que la separa de la anterior.
+func (p *printer) begin(pos token.Token, force bool) {
+	start := pos
+	if pos.Line > 0 {
+		start = p.src.start(pos)
+		if p.comments(start, force) {
+			force = false
+		}
+	}
+	p.separate(start, force)
+}
+
+// open escribe la cabecera de un bloque y aumenta la sangría.
+func (p *printer) open(srcLine int, header string) {
+	p.line(srcLine, header+" {")
+	p.depth++
+}
+
+// close escribe los comentarios que quedan dentro del bloque que abre owner y su llave de cierre.
+func (p *printer) close(owner token.Token) {
+	end, ok := p.src.closingBrace(owner)
+	if ok {
+		p.comments(end, false)
+	}
+	p.depth--
+	p.line(end.Line, "}")
+}
+
+// program escribe el pragma, las importaciones y las clases, separados por una línea en blanco.
+func (p *printer) program(program *ast.Program) {
+	var prev ast.Statement
+	for _, stmt := range program.Statements {
+		tok, _ := ast.TokenOf(stmt)
+		_, isImport := stmt.(*ast.ImportStatement)
+		_, prevImport := prev.(*ast.ImportStatement)
+		p.begin(tok, prev != nil && !(isImport && prevImport))
+
+		switch n := stmt.(type) {
+		case *ast.PragmaStatement:
+			p.line(tok.Line, n.String())
+		case *ast.ImportStatement:
+			p.line(tok.Line, n.String())
+		case *ast.ClassStatement:
+			p.class(n)
+		}
+		prev = stmt
+	}
+
+	p.comments(token.Token{}, false) // Los comentarios del final del archivo.
+}
+
+func (p *printer) class(n *ast.ClassStatement) {
+	header := "class contract " + n.Name
+	if n.IsInterface {
+		header = "class interface " + n.Name
+	}
+	if len(n.Parents) > 0 {
+		header += " is " + strings.Join(n.Parents, ", ")
+	}
+
+	p.open(n.Token.Line, header)
+	for _, stmt := range n.Body {
+		if stmt == nil {
+			continue
+		}
+		tok, _ := ast.TokenOf(stmt)
+		p.begin(tok, false)
+		p.member(stmt)
+	}
+	p.close(n.Token)
+}
+
+// member escribe una declaración del cuerpo de una clase.
+func (p *printer) member(stmt ast.Statement) {
+	tok, _ := ast.TokenOf(stmt)
+	switch n := stmt.(type) {
+	case *ast.VariableStatement:
+		p.line(tok.Line, visibility(n.Public)+kind(n.Kind)+n.Token.Literal+" "+n.Name+": "+p.expr(n.Value)+";")
+	case *ast.VariableStatementNonInitializer:
+		p.line(tok.Line, visibility(n.Public)+kind(n.Kind)+n.Token.Literal+" "+n.Name+";")
+	case *ast.StorageDeclaration:
+		p.line(tok.Line, visibility(n.Public)+"storage "+n.Name+"("+params(n.Params)+"): "+n.Value.Type+";")
+	case *ast.ErrorStatement:
+		p.line(tok.Line, "error "+n.Name+"("+params(n.Params)+");")
+	case *ast.EnumStatement:
+		p.open(tok.Line, "enum "+n.Name+":")
+		for _, value := range n.Values {
+			p.line(0, value+";")
+		}
+		p.close(tok)
+	case *ast.StructStatement:
+		p.open(tok.Line, "struct "+n.Name+":")
+		for _, field := range n.Fields {
+			p.line(0, field.Name+": "+field.Type+";")
+		}
+		p.close(tok)
+	case *ast.ConstructorStatement:
+		p.block(tok, "constructor("+params(n.Params)+")", n.Body)
+	case *ast.ModifierStatement:
+		p.block(tok, "modifier "+n.Name+"("+params(n.Params)+")", n.Body)
+	case *ast.FuncStatement:
+		header := "func " + n.Name + "(" + params(n.Params) + ")"
+		if n.Public {
+			header = "pub " + header
+		} else {
+			header = "priv " + header
+		}
+		if n.Mutability != "" {
+			header += " " + n.Mutability
+		}
+		for _, modifier := range n.Modifiers {
+			header += " " + p.modifier(modifier)
+		}
+		if n.Override {
+			header += " override"
+		}
+		header += ": " + n.ReturnType.Type
+		if n.Body == nil { // Funciones de interfaz, sin cuerpo.
+			p.line(tok.Line, header+";")
+			return
+		}
+		p.block(tok, header, n.Body)
+	}
+}
+
+// block escribe una cabecera seguida de un bloque de sentencias.
+func (p *printer) block(owner token.Token, header string, body []ast.Statement) {
+	p.open(owner.Line, header)
+	for _, stmt := range body {
+		if es, ok := stmt.(*ast.ExpressionStatement); ok && es.Expression == nil {
+			continue // Un ';' suelto.
+		}
+		tok, _ := ast.TokenOf(stmt)
+		p.begin(tok, false)
+		p.line(tok.Line, p.statement(stmt))
+	}
+	p.close(owner)
+}
+
+// statement devuelve una sentencia del cuerpo de una función en una línea.
+func (p *printer) statement(stmt ast.Statement) string {
+	switch n := stmt.(type) {
+	case *ast.ReturnStatement:
+		if n.Value == nil {
+			return "return;"
+		}
+		return "return " + p.expr(n.Value) + ";"
+	case *ast.RevertStatement:
+		return "revert " + p.expr(n.Error) + ";"
+	case *ast.DeleteStatement:
+		return "delete " + n.Name + "(" + identifiers(n.Params) + ");"
+	case *ast.NewStatement:
+		return "new " + n.Name + "(" + identifiers(n.Params) + "): " + p.expr(n.Value) + ";"
+	case *ast.PlaceholderStatement:
+		return "_;"
+	case *ast.AssignStatement:
+		return n.Name + ": " + p.expr(n.Value) + ";"
+	case *ast.ExpressionStatement:
+		return p.expr(n.Expression) + ";"
+	}
+	return stmt.String()
+}
+
+// expr devuelve una expresión en una sola línea. Las operaciones binarias se
+// agrupan por la derecha, así que solo el operando izquierdo necesita paréntesis.
+func (p *printer) expr(e ast.Expression) string {
+	switch n := e.(type) {
+	case nil:
+		return ""
+	case *ast.Identifier:
+		return n.Value
+	case *ast.IntegerLiteral:
+		if n.Token.Literal != "" {
+			return n.Token.Literal
+		}
+		return strconv.FormatUint(n.Value, 10)
+	case *ast.StringLiteral:
+		return `"` + n.Value + `"`
+	case *ast.BooleanLiteral:
+		return strconv.FormatBool(n.Value)
+	case *ast.ByteLiteral:
+		return strconv.FormatUint(n.Value, 10)
+	case *ast.AddressExpression:
+		return n.Value
+	case *ast.HashLiteral:
+		return n.Value
+	case *ast.BinaryExpression:
+		left := p.expr(n.Left)
+		if _, ok := n.Left.(*ast.BinaryExpression); ok {
+			left = "(" + left + ")"
+		}
+		return left + " " + n.Operator + " " + p.expr(n.Right)
+	case *ast.CallExpression:
+		return p.expr(n.Function) + "(" + p.exprs(n.Arguments) + ")"
+	case *ast.StorageAccessStatement:
+		return n.Name + "(" + p.exprs(n.Params) + ")"
+	case *ast.StorageStatement:
+		return n.Name + "(" + p.exprs(n.Params) + "): " + p.expr(n.Value)
+	case *ast.MemberExpression:
+		return p.expr(n.Object) + "." + n.Member
+	case *ast.ExternalCallExpression:
+		return n.Interface + "(" + p.expr(n.Address) + ")." + n.Method + "(" + p.exprs(n.Arguments) + ")"
+	case *ast.CreateExpression:
+		return "create " + n.Contract + "(" + p.exprs(n.Arguments) + ")"
+	case *ast.ErrorCallExpression:
+		return n.Name + "(" + p.exprs(n.Arguments) + ")"
+	case *ast.ConstExpression:
+		return n.Token.Literal + " " + n.Name + ": " + p.expr(n.Value)
+	case *ast.ArrayLiteral:
+		return "[" + p.exprs(n.Elements) + "]"
+	case *ast.ErrLiteral:
+		if n.Return == nil {
+			return "check(" + p.expr(n.Value) + ")"
+		}
+		return "check(" + p.expr(n.Value) + ", " + p.expr(n.Return) + ")"
+	case *ast.ErrValue:
+		return "err: " + p.expr(n.Value)
+	}
+	return e.String()
+}
+
+func (p *printer) exprs(list []ast.Expression) string {
+	out := make([]string, 0, len(list))
+	for _, e := range list {
+		out = append(out, p.expr(e))
+	}
+	return strings.Join(out, ", ")
+}
+
+func (p *printer) modifier(m ast.ModifierInvocation) string {
+	if len(m.Arguments) == 0 {
+		return m.Name
+	}
+	return m.Name + "(" + p.exprs(m.Arguments) + ")"
+}
+
+func visibility(public bool) string {
+	if public {
+		return "pub "
+	}
+	return ""
+}
+
+// kind devuelve const o immutable seguido de un espacio, o "" para las variables
+// guardadas en el storage.
+func kind(kind string) string {
+	if kind == "" {
+		return ""
+	}
+	return kind + " "
+}
+
+func params(keys []ast.Key) string {
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, key.Name+": "+key.Type)
+	}
+	return strings.Join(out, ", ")
+}
+
+func identifiers(list []ast.Identifier) string {
+	out := make([]string, 0, len(list))
+	for _, id := range list {
+		out = append(out, id.Value)
+	}
+	return strings.Join(out, ", ")
+}
@@ -2,6 +2,7 @@
 package lexer
 
 import (
+	"strings"
 	"unicode"
 
 	"github.com/polarysfoundation/ryot/token"
@@ -14,10 +15,26 @@ type Lexer struct {
 	ch           byte
 	line         int
 	column       int
+	comments     []token.Token // Comentarios leídos hasta ahora, en orden.
+	emitComments bool          // NextToken devuelve los comentarios como tokens COMMENT.
 }
 
-func New(input string) *Lexer {
-	l := &Lexer{input: input}
+// Option configura un Lexer.
+type Option func(*Lexer)
+
+// WithComments hace que NextToken devuelva los comentarios como tokens COMMENT
+// en lugar de saltarlos. El parser los acepta y los asocia a los nodos del AST.
+func WithComments() Option {
+	return func(l *Lexer) {
+		l.emitComments = true
+	}
+}
+
+func New(input string, opts ...Option) *Lexer {
+	l := &Lexer{input: input, line: 1}
+	for _, opt := range opts {
+		opt(l)
+	}
 	l.readChar()
 	return l
 }
@@ -40,9 +57,25 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) NextToken() token.Token {
-	l.skipWhitespace()
-	l.skipComment()
+	for {
+		l.skipWhitespace()
+		comment, ok := l.readComment()
+		if !ok {
+			break
+		}
+		if l.emitComments || comment.Type == token.ILLEGAL {
+			return comment
+		}
+	}
 
+	line, column := l.line, l.column
+	tok := l.readToken()
+	tok.Line, tok.Column = line, column
+	return tok
+}
+
+// readToken lee el token que empieza en el carácter actual.
+func (l *Lexer) readToken() token.Token {
 	var tok token.Token
 
 	switch l.ch {
@@ -78,6 +111,8 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
+	case '.':
+		tok = newToken(token.DOT, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -166,12 +201,42 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-func (l *Lexer) skipComment() {
-	if l.ch == '/' && l.peekChar() == '/' {
+// readComment lee un comentario // o /* */ y lo guarda en Comments. Devuelve
+// false si el carácter actual no empieza un comentario. Un /* sin cerrar
+// devuelve un token ILLEGAL.
+func (l *Lexer) readComment() (token.Token, bool) {
+	if l.ch != '/' || (l.peekChar() != '/' && l.peekChar() != '*') {
+		return token.Token{}, false
+	}
+	comment := token.Token{Type: token.COMMENT, Line: l.line, Column: l.column}
+	position := l.position
+
+	if l.peekChar() == '/' {
 		for l.ch != '\n' && l.ch != 0 {
 			l.readChar()
 		}
+	} else {
+		l.readChar() // '/'
+		l.readChar() // '*'
+		for !(l.ch == '*' && l.peekChar() == '/') {
+			if l.ch == 0 {
+				comment.Type, comment.Literal = token.ILLEGAL, "/*"
+				return comment, true
+			}
+			l.readChar()
+		}
+		l.readChar() // '*'
+		l.readChar() // '/'
 	}
+
+	comment.Literal = strings.TrimRightFunc(l.input[position:l.position], unicode.IsSpace)
+	l.comments = append(l.comments, comment)
+	return comment, true
+}
+
+// Comments devuelve los comentarios que el lexer ha leído hasta ahora.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
 }
 
 func (l *Lexer) readIdentifier() string {
@@ -187,11 +252,13 @@ func (l *Lexer) isAddress() bool {
 	currentPos := l.position
 	currentReadPos := l.readPosition
 	currentCh := l.ch
+	currentLine, currentColumn := l.line, l.column
 
 	defer func() {
 		l.position = currentPos
 		l.readPosition = currentReadPos
 		l.ch = currentCh
+		l.line, l.column = currentLine, currentColumn
 	}()
 
 	// Verificar el patrón completo "1cx" + 30 caracteres hex
@@ -227,11 +294,13 @@ func (l *Lexer) isHash() bool {
 	currentPos := l.position
 	currentReadPos := l.readPosition
 	currentCh := l.ch
+	currentLine, currentColumn := l.line, l.column
 
 	defer func() {
 		l.position = currentPos
 		l.readPosition = currentReadPos
 		l.ch = currentCh
+		l.line, l.column = currentLine, currentColumn
 	}()
 
 	// Verificar el patrón completo "0x" + 64 caracteres hex
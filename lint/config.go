@@ -0,0 +1,83 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ConfigFile es el nombre del archivo de configuración que ryotc lint busca en
+// el directorio actual si no se indica otro.
+const ConfigFile = ".ryotlint.json"
+
+// Config indica qué reglas se ejecutan. Se lee de un archivo JSON como este:
+//
+//	{
+//	  "rules": {
+//	    "magic-number": false,
+//	    "shadowing": true
+//	  }
+//	}
+//
+// Las reglas que no aparecen están activas, así que la configuración vacía las
+// ejecuta todas.
+type Config struct {
+	Rules map[string]bool `json:"rules"`
+}
+
+// Enabled indica si la regla name está activa.
+func (c Config) Enabled(name string) bool {
+	enabled, ok := c.Rules[name]
+	return !ok || enabled
+}
+
+// ParseConfig lee una configuración en JSON. Los campos y las reglas desconocidos
+// son un error, para que una errata no desactive nada en silencio.
+func ParseConfig(data []byte) (Config, error) {
+	var config Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("lint: configuración inválida: %w", err)
+	}
+
+	known := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.Name] = true
+	}
+	var unknown []string
+	for name := range config.Rules {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return Config{}, fmt.Errorf("lint: regla desconocida %s", strings.Join(quote(unknown), ", "))
+	}
+	return config, nil
+}
+
+// LoadConfig lee la configuración del archivo path.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("lint: %w", err)
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+func quote(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return quoted
+}
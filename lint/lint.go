@@ -0,0 +1,222 @@
+// Package lint busca en los programas Ryot código que compila pero que
+// probablemente es un error o un riesgo en un contrato:
+//
+//	unused-function      funciones priv a las que nadie llama
+//	unused-storage       storages priv que nunca se usan
+//	unread-storage       storages priv que se escriben pero nunca se leen
+//	missing-access-check funciones pub que modifican el estado sin comprobar caller()
+//	shadowing            parámetros y variables locales que ocultan otro nombre
+//	magic-number         números sin nombre en la condición de un check
+//	missing-return       funciones con tipo de retorno que pueden terminar sin return
+//	unreachable-code     sentencias después de un return, un revert o un check que siempre falla
+//
+// Todas las reglas están activas salvo que la configuración las desactive
+// (véase Config). Cada hallazgo lleva la posición de la declaración o la
+// sentencia que lo produce.
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/flow"
+	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/parser"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// Nombres de las reglas.
+const (
+	RuleUnusedFunction     = "unused-function"
+	RuleUnusedStorage      = "unused-storage"
+	RuleUnreadStorage      = "unread-storage"
+	RuleMissingAccessCheck = "missing-access-check"
+	RuleShadowing          = "shadowing"
+	RuleMagicNumber        = "magic-number"
+	RuleMissingReturn      = "missing-return"
+	RuleUnreachableCode    = "unreachable-code"
+)
+
+// Rule describe una regla del linter.
+type Rule struct {
+	Name        string
+	Description string
+	check       func(l *linter, class *ast.ClassStatement)
+}
+
+// rules contiene todas las reglas en el orden en que se ejecutan.
+var rules = []Rule{
+	{RuleUnusedFunction, "funciones priv a las que nadie llama", checkUnusedFunctions},
+	{RuleUnusedStorage, "storages priv que nunca se usan", checkUnusedStorages},
+	{RuleUnreadStorage, "storages priv que se escriben pero nunca se leen", checkUnreadStorages},
+	{RuleMissingAccessCheck, "funciones pub que modifican el estado sin comprobar caller()", checkAccess},
+	{RuleShadowing, "parámetros y variables locales que ocultan otro nombre", checkShadowing},
+	{RuleMagicNumber, "números sin nombre en la condición de un check", checkMagicNumbers},
+	{RuleMissingReturn, "funciones con tipo de retorno que pueden terminar sin return", checkMissingReturns},
+	{RuleUnreachableCode, "sentencias después de un return, un revert o un check que siempre falla", checkUnreachable},
+}
+
+// Rules devuelve todas las reglas del linter.
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+// Finding es un problema encontrado por una regla. Line y Column empiezan en 1.
+type Finding struct {
+	Rule    string `json:"rule"`
+	Class   string `json:"class,omitempty"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// String devuelve el hallazgo como línea:columna: mensaje (regla).
+func (f Finding) String() string {
+	return fmt.Sprintf("%d:%d: %s (%s)", f.Line, f.Column, f.Message, f.Rule)
+}
+
+// Source analiza el código fuente src. Devuelve un error si src tiene errores
+// de sintaxis.
+func Source(src []byte, config Config) ([]Finding, error) {
+	p := parser.New(lexer.New(string(src)))
+	program, ok := p.ParseProgram().(*ast.Program)
+	if !ok {
+		return nil, fmt.Errorf("lint: el parser no devolvió un programa")
+	}
+	if errs := p.SyntaxErrors(); len(errs) > 0 {
+		return nil, fmt.Errorf("lint: %v", errs[0])
+	}
+	return Program(program, config), nil
+}
+
+// Program analiza un programa con las reglas activas en config y devuelve los
+// hallazgos ordenados por posición.
+func Program(program *ast.Program, config Config) []Finding {
+	l := &linter{classes: make(map[string]*ast.ClassStatement)}
+	for _, stmt := range program.Statements {
+		if class, ok := stmt.(*ast.ClassStatement); ok {
+			l.classes[class.Name] = class
+			l.order = append(l.order, class)
+		}
+	}
+	l.collectUses()
+	l.flow = flow.Check(program)
+
+	for _, class := range l.order {
+		if class.IsInterface {
+			continue // Las interfaces solo declaran funciones de otros contratos.
+		}
+		for _, rule := range rules {
+			if config.Enabled(rule.Name) {
+				l.rule = rule.Name
+				rule.check(l, class)
+			}
+		}
+	}
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.findings
+}
+
+// linter guarda el estado de un análisis.
+type linter struct {
+	classes  map[string]*ast.ClassStatement
+	order    []*ast.ClassStatement // Clases en el orden del programa.
+	rule     string                // Regla en ejecución.
+	findings []Finding
+
+	calls  map[string]bool // Nombres llamados como función en alguna clase.
+	reads  map[string]bool // Storages leídos en alguna clase.
+	writes map[string]bool // Storages escritos en alguna clase.
+	flow   []flow.Issue    // Problemas de flujo de control del programa.
+}
+
+// report añade un hallazgo de la regla en ejecución en la posición de tok.
+func (l *linter) report(class *ast.ClassStatement, tok token.Token, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{
+		Rule:    l.rule,
+		Class:   class.Name,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// members devuelve los miembros de class y de las clases de las que hereda en el
+// mismo programa, por nombre. Los de la clase más derivada tienen prioridad.
+func (l *linter) members(class *ast.ClassStatement) map[string]ast.Statement {
+	members := make(map[string]ast.Statement)
+	visited := make(map[string]bool)
+	var visit func(class *ast.ClassStatement)
+	visit = func(class *ast.ClassStatement) {
+		if visited[class.Name] {
+			return
+		}
+		visited[class.Name] = true
+		for _, stmt := range class.Body {
+			if name := memberName(stmt); name != "" {
+				if _, ok := members[name]; !ok {
+					members[name] = stmt
+				}
+			}
+		}
+		for _, parent := range class.Parents {
+			if p, ok := l.classes[parent]; ok {
+				visit(p)
+			}
+		}
+	}
+	visit(class)
+	return members
+}
+
+// memberName devuelve el nombre con el que se usa un miembro dentro de la clase.
+func memberName(stmt ast.Statement) string {
+	switch m := stmt.(type) {
+	case *ast.FuncStatement:
+		return m.Name
+	case *ast.StorageDeclaration:
+		return m.Name
+	case *ast.ModifierStatement:
+		return m.Name
+	case *ast.VariableStatement:
+		return m.Name
+	case *ast.VariableStatementNonInitializer:
+		return m.Name
+	case *ast.ErrorStatement:
+		return m.Name
+	case *ast.EnumStatement:
+		return m.Name
+	case *ast.StructStatement:
+		return m.Name
+	}
+	return ""
+}
+
+// memberKind describe un miembro en los mensajes.
+func memberKind(stmt ast.Statement) string {
+	switch stmt.(type) {
+	case *ast.FuncStatement:
+		return "la función"
+	case *ast.StorageDeclaration:
+		return "el storage"
+	case *ast.ModifierStatement:
+		return "el modificador"
+	case *ast.VariableStatement, *ast.VariableStatementNonInitializer:
+		return "la variable"
+	case *ast.ErrorStatement:
+		return "el error"
+	case *ast.EnumStatement:
+		return "el enum"
+	case *ast.StructStatement:
+		return "el struct"
+	}
+	return "el miembro"
+}
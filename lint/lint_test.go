@@ -0,0 +1,186 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const bank = `pragma: "1.0.0";
+
+class contract Bank {
+
+    pub storage owners(account: address): bool;
+    pub storage balances(account: address): uint64;
+    priv storage audit(id: uint64): uint64;
+    priv storage unused(id: uint64): bool;
+    priv storage limit(id: uint64): uint64;
+
+    modifier onlyOwner(account: address) {
+        check(owners(account), err: "Not owner");
+        _;
+    }
+
+    pub func deposit(amount: uint64): void {
+        balances(caller()): balances(caller()) + amount;
+    }
+
+    pub func withdraw(amount: uint64): void {
+        address sender: caller();
+        check(balances(sender) >= amount, err: "Insufficient");
+        balances(sender): balances(sender) - amount;
+    }
+
+    pub func setLimit(amount: uint64) onlyOwner(caller()): void {
+        limit(0): amount;
+        _record(amount);
+    }
+
+    pub func mint(to: address, amount: uint64): void {
+        check(amount < 1000, err: "Too much");
+        balances(to): amount;
+    }
+
+    pub func grant(account: address): void {
+        _grant(account);
+    }
+
+    priv func _grant(account: address): void {
+        owners(account): true;
+    }
+
+    priv func _record(amount: uint64): void {
+        audit(0): amount;
+    }
+
+    priv func _orphan(): void {
+    }
+
+    pub func total(caller: address): uint64 {
+        uint64 limit: 1;
+        uint64 caller: 2;
+    }
+
+    pub func current(): uint64 {
+        return limit(0);
+        limit(0): 0;
+    }
+}
+`
+
+func TestProgram(t *testing.T) {
+	findings, err := Source([]byte(bank), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		if f.Class != "Bank" {
+			t.Errorf("expected class Bank, got %q", f.Class)
+		}
+		got = append(got, f.String())
+	}
+	want := []string{
+		"7:10: el storage privado 'audit' se escribe pero nunca se lee (unread-storage)",
+		"8:10: el storage privado 'unused' no se usa (unused-storage)",
+		"31:9: la función pública 'mint' modifica el estado sin comprobar caller() (missing-access-check)",
+		"32:24: número mágico 1000 en la condición de check; use una variable con nombre (magic-number)",
+		"36:9: la función pública 'grant' modifica el estado sin comprobar caller() (missing-access-check)",
+		"48:10: la función privada '_orphan' no se usa (unused-function)",
+		"51:9: la función 'total' devuelve uint64 pero puede terminar sin return (missing-return)",
+		"51:20: el parámetro 'caller' oculta la función predefinida 'caller' (shadowing)",
+		"52:9: la variable local 'limit' oculta el storage 'limit' (shadowing)",
+		"53:9: la variable local 'caller' oculta el parámetro 'caller' (shadowing)",
+		"58:9: código inalcanzable después de return (unreachable-code)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected findings:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestProgramInheritance(t *testing.T) {
+	src := `pragma: "1.0.0";
+
+class contract Ownable {
+
+    pub storage owners(account: address): bool;
+
+    modifier onlyOwner() {
+        check(owners(caller()), err: "Not owner");
+        _;
+    }
+
+    priv func _pause(): void {
+    }
+}
+
+class contract Pausable is Ownable {
+
+    pub storage paused(id: uint64): bool;
+
+    pub func pause() onlyOwner: void {
+        paused(0): true;
+        _pause();
+    }
+}
+`
+	findings, err := Source([]byte(src), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 0 {
+		t.Fatalf("inherited modifiers and private functions must count, got %v", findings)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`{"rules": {"missing-access-check": false, "shadowing": false, "magic-number": true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := Source([]byte(bank), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := make(map[string]int)
+	for _, f := range findings {
+		count[f.Rule]++
+	}
+	if count[RuleMissingAccessCheck] != 0 || count[RuleShadowing] != 0 || count[RuleMagicNumber] != 1 || len(findings) != 6 {
+		t.Fatalf("unexpected findings with the config: %v", findings)
+	}
+
+	for _, name := range []string{"missing-access-check", "unread-storage", "unreachable-code"} {
+		if !(Config{}).Enabled(name) {
+			t.Fatalf("expected %s to be enabled by default", name)
+		}
+	}
+	if len(Rules()) != 8 {
+		t.Fatalf("expected 8 rules, got %d", len(Rules()))
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{`{"rules": {"shadow": false, "magic": false}}`, `regla desconocida "magic", "shadow"`},
+		{`{"rule": {}}`, "configuración inválida"},
+		{`{"rules": {"shadowing": "no"}}`, "configuración inválida"},
+	}
+	for _, tt := range tests {
+		_, err := ParseConfig([]byte(tt.config))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.config, tt.want, err)
+		}
+	}
+}
+
+func TestSourceSyntaxError(t *testing.T) {
+	_, err := Source([]byte("pragma: \"1.0.0\";\nclass contract A {\n  pub func f(: void {}\n}\n"), Config{})
+	if err == nil || !strings.HasPrefix(err.Error(), "lint: ") {
+		t.Fatalf("expected a syntax error, got %v", err)
+	}
+}
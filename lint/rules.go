@@ -0,0 +1,312 @@
+package lint
+
+import (
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/flow"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// collectUses anota qué nombres se llaman y qué storages se leen o se escriben
+// en todas las clases del programa, ya que una clase hereda los miembros
+// privados de sus padres.
+func (l *linter) collectUses() {
+	l.calls = make(map[string]bool)
+	l.reads = make(map[string]bool)
+	l.writes = make(map[string]bool)
+	for _, class := range l.order {
+		for _, stmt := range class.Body {
+			ast.Inspect(stmt, func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.StorageAccessStatement:
+					// Las llamadas a funciones y las lecturas de storages se escriben igual.
+					l.calls[n.Name] = true
+					l.reads[n.Name] = true
+				case *ast.StorageStatement:
+					if n.Value != nil {
+						l.writes[n.Name] = true
+					} else {
+						l.reads[n.Name] = true
+					}
+				case *ast.DeleteStatement:
+					l.writes[n.Name] = true
+				case *ast.NewStatement:
+					l.writes[n.Name] = true
+				case *ast.CallExpression:
+					l.calls[n.Function.String()] = true
+				case *ast.Identifier:
+					l.reads[n.Value] = true
+				}
+				return true
+			})
+		}
+	}
+}
+
+func checkUnusedFunctions(l *linter, class *ast.ClassStatement) {
+	for _, stmt := range class.Body {
+		if fn, ok := stmt.(*ast.FuncStatement); ok && !fn.Public && !l.calls[fn.Name] {
+			l.report(class, fn.Token, "la función privada '%s' no se usa", fn.Name)
+		}
+	}
+}
+
+func checkUnusedStorages(l *linter, class *ast.ClassStatement) {
+	for _, stmt := range class.Body {
+		if st, ok := stmt.(*ast.StorageDeclaration); ok && !st.Public && !l.reads[st.Name] && !l.writes[st.Name] {
+			l.report(class, st.Token, "el storage privado '%s' no se usa", st.Name)
+		}
+	}
+}
+
+func checkUnreadStorages(l *linter, class *ast.ClassStatement) {
+	for _, stmt := range class.Body {
+		if st, ok := stmt.(*ast.StorageDeclaration); ok && !st.Public && l.writes[st.Name] && !l.reads[st.Name] {
+			l.report(class, st.Token, "el storage privado '%s' se escribe pero nunca se lee", st.Name)
+		}
+	}
+}
+
+// checkAccess avisa de las funciones públicas que escriben en un storage, lo
+// borran o destruyen el contrato sin ningún check cuya condición dependa de
+// caller(). Cuentan los checks de la función, de sus modificadores y de las
+// funciones a las que llama. Las escrituras cuya clave depende de caller(),
+// como balances(caller()): x, solo afectan a quien llama y no necesitan check.
+func checkAccess(l *linter, class *ast.ClassStatement) {
+	a := &access{members: l.members(class), visiting: make(map[string]bool)}
+	for _, stmt := range class.Body {
+		fn, ok := stmt.(*ast.FuncStatement)
+		if !ok || !fn.Public || fn.Body == nil {
+			continue
+		}
+		var e effects
+		a.visiting[fn.Name] = true
+		a.function(fn, nil, &e)
+		delete(a.visiting, fn.Name)
+		if e.writes && !e.guarded {
+			l.report(class, fn.Token, "la función pública '%s' modifica el estado sin comprobar caller()", fn.Name)
+		}
+	}
+}
+
+// effects resume lo que hace una función respecto al control de acceso.
+type effects struct {
+	writes  bool // Modifica el estado de todas las cuentas.
+	guarded bool // Ejecuta un check que depende de caller().
+}
+
+// access recorre una función y las que llama para calcular sus efectos.
+type access struct {
+	members  map[string]ast.Statement
+	visiting map[string]bool // Funciones en curso, para no seguir la recursión.
+}
+
+// function añade a e los efectos de fn. bound son los parámetros cuyo valor
+// depende de caller().
+func (a *access) function(fn *ast.FuncStatement, bound map[string]bool, e *effects) {
+	for _, inv := range fn.Modifiers {
+		mod, ok := a.members[inv.Name].(*ast.ModifierStatement)
+		if !ok {
+			continue
+		}
+		a.statements(mod.Body, a.bind(mod.Params, inv.Arguments, bound), e)
+	}
+	a.statements(fn.Body, bound, e)
+}
+
+// bind devuelve qué parámetros reciben un argumento que depende de caller().
+func (a *access) bind(params []ast.Key, args []ast.Expression, bound map[string]bool) map[string]bool {
+	result := make(map[string]bool)
+	for i, param := range params {
+		if i < len(args) && mentionsCaller(args[i], bound) {
+			result[param.Name] = true
+		}
+	}
+	return result
+}
+
+func (a *access) statements(body []ast.Statement, bound map[string]bool, e *effects) {
+	locals := make(map[string]bool, len(bound))
+	for name := range bound {
+		locals[name] = true
+	}
+	if i := terminator(body); i >= 0 {
+		body = body[:i+1] // Lo que sigue no se ejecuta.
+	}
+	for _, stmt := range body {
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.ErrLiteral:
+				if mentionsCaller(n.Value, locals) {
+					e.guarded = true
+				}
+			case *ast.StorageStatement:
+				if _, ok := a.members[n.Name].(*ast.StorageDeclaration); ok && n.Value != nil && !anyMentionsCaller(n.Params, locals) {
+					e.writes = true
+				}
+			case *ast.AssignStatement:
+				e.writes = true
+			case *ast.DeleteStatement:
+				if !anyBound(n.Params, locals) {
+					e.writes = true
+				}
+			case *ast.NewStatement:
+				if !anyBound(n.Params, locals) {
+					e.writes = true
+				}
+			case *ast.CallExpression:
+				if name := n.Function.String(); (name == "selfdestruct" || name == "transfer") && a.members[name] == nil {
+					e.writes = true
+				}
+			case *ast.StorageAccessStatement:
+				callee, ok := a.members[n.Name].(*ast.FuncStatement)
+				if ok && callee.Body != nil && !a.visiting[n.Name] {
+					a.visiting[n.Name] = true
+					a.function(callee, a.bind(callee.Params, n.Params, locals), e)
+					delete(a.visiting, n.Name)
+				}
+			}
+			return true
+		})
+		// uint64 sender: caller(); hace que sender dependa de caller().
+		if es, ok := stmt.(*ast.ExpressionStatement); ok {
+			if c, ok := es.Expression.(*ast.ConstExpression); ok && c.Value != nil && mentionsCaller(c.Value, locals) {
+				locals[c.Name] = true
+			}
+		}
+	}
+}
+
+// mentionsCaller indica si el valor de expr depende de caller().
+func mentionsCaller(expr ast.Expression, bound map[string]bool) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.CallExpression:
+			if n.Function.String() == "caller" {
+				found = true
+			}
+		case *ast.Identifier:
+			if bound[n.Value] {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+func anyMentionsCaller(exprs []ast.Expression, bound map[string]bool) bool {
+	for _, expr := range exprs {
+		if mentionsCaller(expr, bound) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyBound(idents []ast.Identifier, bound map[string]bool) bool {
+	for _, ident := range idents {
+		if bound[ident.Value] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkShadowing avisa de los parámetros y variables locales que tienen el
+// nombre de un miembro de la clase, de una función predefinida o de otro
+// parámetro o variable local de la misma función.
+func checkShadowing(l *linter, class *ast.ClassStatement) {
+	members := l.members(class)
+	for _, stmt := range class.Body {
+		var params []ast.Key
+		var body []ast.Statement
+		switch m := stmt.(type) {
+		case *ast.FuncStatement:
+			params, body = m.Params, m.Body
+		case *ast.ModifierStatement:
+			params, body = m.Params, m.Body
+		case *ast.ConstructorStatement:
+			params, body = m.Params, m.Body
+		default:
+			continue
+		}
+
+		declared := make(map[string]string) // Nombre -> "el parámetro" o "la variable local".
+		shadows := func(what, name string, tok token.Token) {
+			switch {
+			case declared[name] != "":
+				l.report(class, tok, "%s '%s' oculta %s '%s'", what, name, declared[name], name)
+			case members[name] != nil:
+				l.report(class, tok, "%s '%s' oculta %s '%s'", what, name, memberKind(members[name]), name)
+			case token.IsBuiltin(name):
+				l.report(class, tok, "%s '%s' oculta la función predefinida '%s'", what, name, name)
+			}
+			if declared[name] == "" {
+				declared[name] = what
+			}
+		}
+		for _, param := range params {
+			shadows("el parámetro", param.Name, param.Token)
+		}
+		for _, stmt := range body {
+			if es, ok := stmt.(*ast.ExpressionStatement); ok {
+				if c, ok := es.Expression.(*ast.ConstExpression); ok {
+					shadows("la variable local", c.Name, c.Token)
+				}
+			}
+		}
+	}
+}
+
+// checkMagicNumbers avisa de los números distintos de 0 y 1 en la condición de
+// un check: un límite o un rol con nombre dice qué se comprueba.
+func checkMagicNumbers(l *linter, class *ast.ClassStatement) {
+	for _, stmt := range class.Body {
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			check, ok := node.(*ast.ErrLiteral)
+			if !ok {
+				return true
+			}
+			ast.Inspect(check.Value, func(node ast.Node) bool {
+				if n, ok := node.(*ast.IntegerLiteral); ok && n.Value > 1 {
+					l.report(class, n.Token, "número mágico %d en la condición de check; use una variable con nombre", n.Value)
+				}
+				return true
+			})
+			return false
+		})
+	}
+}
+
+// checkMissingReturns avisa de las funciones con tipo de retorno que pueden
+// terminar sin return. El compilador también las rechaza.
+func checkMissingReturns(l *linter, class *ast.ClassStatement) {
+	l.reportFlow(class, flow.MissingReturn)
+}
+
+// checkUnreachable avisa de la primera sentencia después de un return, un revert
+// o un check que siempre falla.
+func checkUnreachable(l *linter, class *ast.ClassStatement) {
+	l.reportFlow(class, flow.Unreachable)
+}
+
+// reportFlow añade los problemas de flujo de control de class del tipo kind.
+func (l *linter) reportFlow(class *ast.ClassStatement, kind string) {
+	for _, issue := range l.flow {
+		if issue.Class == class.Name && issue.Kind == kind {
+			l.report(class, token.Token{Line: issue.Line, Column: issue.Column}, "%s", issue.Message)
+		}
+	}
+}
+
+// terminator devuelve el índice del primer return o revert de body, o -1.
+func terminator(body []ast.Statement) int {
+	for i, stmt := range body {
+		switch stmt.(type) {
+		case *ast.ReturnStatement, *ast.RevertStatement:
+			return i
+		}
+	}
+	return -1
+}
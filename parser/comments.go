@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// comment is a COMMENT token read from a lexer in comment mode
+type comment struct {
+	tok      token.Token
+	trailing bool // the comment follows code on the same line
+}
+
+// span is the extent of a statement in the source, kept to attach comments
+type span struct {
+	node   ast.Node
+	parent int         // index of the enclosing statement, -1 at the top level
+	start  token.Token // first token, pub included
+	end    token.Token // last token, computed by setEnds
+}
+
+// beginSpan records that a statement starts at the current token and makes it
+// the enclosing statement of the ones parsed until endSpan
+func (p *Parser) beginSpan() int {
+	p.spans = append(p.spans, span{parent: p.scope, start: p.cur})
+	p.scope = len(p.spans) - 1
+	return p.scope
+}
+
+// endSpan records the node parsed for the statement i
+func (p *Parser) endSpan(i int, node ast.Statement) {
+	if _, ok := ast.TokenOf(node); ok {
+		p.spans[i].node = node
+	}
+	p.scope = p.spans[i].parent
+}
+
+// attachComments attaches every comment read by the parser to the nearest
+// statement. A comment on its own line leads the next statement of its block;
+// a comment after code trails the statement that ends on that line. Comments
+// at the end of a block trail its last statement.
+func (p *Parser) attachComments(program *ast.Program) {
+	if len(p.comments) == 0 {
+		return
+	}
+	p.setEnds()
+
+	program.Comments = make(map[ast.Node]*ast.CommentGroup)
+	for _, c := range p.comments {
+		node, leading := p.commentOwner(c)
+		if node == nil {
+			node = program
+		}
+		group, ok := program.Comments[node]
+		if !ok {
+			group = &ast.CommentGroup{}
+			program.Comments[node] = group
+		}
+		if leading {
+			group.Leading = append(group.Leading, c.tok)
+		} else {
+			group.Trailing = append(group.Trailing, c.tok)
+		}
+	}
+}
+
+// setEnds computes where each statement ends: at the last token before the
+// next statement of its block, or before the closing token of its block
+func (p *Parser) setEnds() {
+	for i := range p.spans {
+		next := token.Token{}
+		for j := i + 1; j < len(p.spans); j++ {
+			if p.spans[j].parent == p.spans[i].parent {
+				next = p.spans[j].start
+				break
+			}
+		}
+		switch {
+		case next.Line > 0:
+			p.spans[i].end = p.lastBefore(next)
+		case p.spans[i].parent >= 0:
+			p.spans[i].end = p.lastBefore(p.spans[p.spans[i].parent].end)
+		case len(p.tokens) > 0:
+			p.spans[i].end = p.tokens[len(p.tokens)-1]
+		}
+		if before(p.spans[i].end, p.spans[i].start) {
+			p.spans[i].end = p.spans[i].start
+		}
+	}
+}
+
+// commentOwner returns the statement a comment belongs to and whether it leads
+// it. A nil node means the comment belongs to the program itself.
+func (p *Parser) commentOwner(c comment) (ast.Node, bool) {
+	if c.trailing {
+		// The outermost statement ending on the comment's line, e.g. a function for "} // ..."
+		best := -1
+		for i, s := range p.spans {
+			if s.node != nil && s.end.Line == c.tok.Line && before(s.end, c.tok) && (best < 0 || p.depth(i) < p.depth(best)) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			return p.spans[best].node, false
+		}
+		// The innermost statement starting on that line, e.g. a function for "{ // ..."
+		for i := len(p.spans) - 1; i >= 0; i-- {
+			s := p.spans[i]
+			if s.node != nil && s.start.Line == c.tok.Line && before(s.start, c.tok) {
+				return s.node, false
+			}
+		}
+	}
+
+	// The innermost statement containing the comment is its block
+	parent := -1
+	for i, s := range p.spans {
+		if before(s.start, c.tok) && before(c.tok, s.end) {
+			parent = i
+		}
+	}
+	last := -1
+	for i, s := range p.spans {
+		if s.parent != parent || s.node == nil {
+			continue
+		}
+		if before(c.tok, s.start) {
+			return s.node, true
+		}
+		last = i
+	}
+	if last >= 0 {
+		return p.spans[last].node, false
+	}
+	if parent >= 0 {
+		return p.spans[parent].node, false
+	}
+	return nil, true
+}
+
+// depth returns how many statements enclose the statement i
+func (p *Parser) depth(i int) int {
+	d := 0
+	for p.spans[i].parent >= 0 {
+		i = p.spans[i].parent
+		d++
+	}
+	return d
+}
+
+// lastBefore returns the last token read before pos
+func (p *Parser) lastBefore(pos token.Token) token.Token {
+	i := sort.Search(len(p.tokens), func(i int) bool { return !before(p.tokens[i], pos) })
+	if i == 0 {
+		return pos
+	}
+	return p.tokens[i-1]
+}
+
+// before reports whether a comes before b in the source
+func before(a, b token.Token) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+}
@@ -0,0 +1,16 @@
+package parser
+
+import "fmt"
+
+// Error is a syntax error found while parsing, with the position of the
+// offending token. Line and Column start at 1.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error returns the message prefixed with line:column
+func (e Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
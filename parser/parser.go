@@ -13,20 +13,30 @@ import (
 // Parser is the main structure for parsing tokens into an AST
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors []Error
 	peek   token.Token
 	cur    token.Token
 	line   int
 	col    int
+	tracer Tracer // receives the parse trace; nil keeps the parser silent
+
+	tokens   []token.Token // every token read, used to attach comments
+	comments []comment     // comments read from a lexer in comment mode
+	spans    []span        // statements parsed so far, in source order
+	scope    int           // index in spans of the enclosing statement, -1 at the top level
 }
 
 // New creates a new Parser instance
-func New(l *lexer.Lexer) *Parser {
+func New(l *lexer.Lexer, opts ...Option) *Parser {
 	p := &Parser{
-		l:      l,                 // lexer instance
-		errors: make([]string, 0), // initialize errors slice
-		line:   1,                 // initialize line number
-		col:    1,                 // initialize column number
+		l:      l,                // lexer instance
+		errors: make([]Error, 0), // initialize errors slice
+		line:   1,                // initialize line number
+		col:    1,                // initialize column number
+		scope:  -1,               // start at the top level
+	}
+	for _, opt := range opts {
+		opt(p) // apply the parser options, e.g. WithTracer
 	}
 	p.nextToken() // read the first token
 	p.nextToken() // read the second token
@@ -37,11 +47,25 @@ func New(l *lexer.Lexer) *Parser {
 func (p *Parser) nextToken() {
 	p.cur = p.peek           // set the current token to the previous token
 	p.peek = p.l.NextToken() // get the next token from the lexer
+
+	// A lexer in comment mode returns comments as tokens: keep them aside to attach them to the AST
+	for p.peek.Type == token.COMMENT {
+		p.comments = append(p.comments, comment{tok: p.peek, trailing: p.cur.Type != "" && p.cur.Line == p.peek.Line})
+		p.peek = p.l.NextToken()
+	}
+
+	switch p.peek.Type {
+	case token.EOF:
+	case token.ILLEGAL:
+		p.addError(p.peek, fmt.Sprintf("illegal token %q", p.peek.Literal))
+	default:
+		p.tokens = append(p.tokens, p.peek)
+	}
 }
 
 // expectPeek checks if the next token is of the expected type and advances the parser if it is
 func (p *Parser) expectPeek(t token.TokenType) bool {
-	if p.peek.Type == t { // check if the next token is of the expected type
+	if p.peek.Is(t) { // check if the next token is of the expected type, contextual keywords included
 		p.nextToken() // advance the parser to the next token
 		return true   // return true if the next token is of the expected type
 	} else { // if the next token is not of the expected type
@@ -53,11 +77,28 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 // peekError adds an error message to the errors slice if the next token is not of the expected type
 func (p *Parser) peekError(t token.TokenType) {
 	msg := "expected next token to be " + string(t) + ", got " + string(p.peek.Type) // construct the error message
-	p.errors = append(p.errors, msg)                                                 // add the error message to the errors slice
+	if t == token.IDENT && token.IsReserved(p.peek.Literal) {
+		msg = "expected identifier, got keyword " + p.peek.Literal // a reserved word cannot be used as a name
+	}
+	p.addError(p.peek, msg) // add the error message to the errors slice
 }
 
-// Errors returns a slice of error messages collected during parsing
+// addError records a syntax error at the position of tok
+func (p *Parser) addError(tok token.Token, msg string) {
+	p.errors = append(p.errors, Error{Line: tok.Line, Column: tok.Column, Message: msg})
+}
+
+// Errors returns a slice of error messages collected during parsing, prefixed with their position
 func (p *Parser) Errors() []string {
+	msgs := make([]string, 0, len(p.errors))
+	for _, err := range p.errors {
+		msgs = append(msgs, err.Error())
+	}
+	return msgs
+}
+
+// SyntaxErrors returns the errors collected during parsing with their positions
+func (p *Parser) SyntaxErrors() []Error {
 	return p.errors
 }
 
@@ -70,19 +111,33 @@ func (p *Parser) ParseProgram() ast.Node {
 
 		switch p.cur.Type {
 		case token.PRAGMA:
+			i := p.beginSpan()
 			stmt := p.parsePragma() // parse a Pragma statement
 			program.Statements = append(program.Statements, stmt)
+			p.endSpan(i, stmt)
+		case token.IMPORT:
+			i := p.beginSpan()
+			stmt := p.parseImport()
+			program.Statements = append(program.Statements, stmt)
+			p.endSpan(i, stmt)
 		case token.CLASS:
+			i := p.beginSpan()
 			stmt := p.parseClass()
 			program.Statements = append(program.Statements, stmt)
+			p.endSpan(i, stmt)
 		default:
 			p.nextToken() // advance the parser to the next token
 		}
 
 	}
 
-	b, _ := json.Marshal(program)
-	fmt.Println(string(b)) // print the program in JSON format for debugging
+	ResolveMemberCalls(program) // members named like a builtin take precedence over it
+	p.attachComments(program)   // attach the comments read in comment mode
+
+	if p.tracer != nil {
+		b, _ := json.Marshal(program)
+		p.tracef("program: %s", b) // trace the program in JSON format for debugging
+	}
 
 	return program // return the Program node
 }
@@ -107,6 +162,33 @@ func (p *Parser) parsePragma() ast.Statement {
 	return stmt // return the PragmaStatement node
 }
 
+// parseImport parses import "./token.ry"; or import { IToken, Ownable } from "./token.ry";
+func (p *Parser) parseImport() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.cur}
+
+	if p.peek.Type == token.LBRACE {
+		p.nextToken()
+		for p.expectPeek(token.IDENT) {
+			stmt.Names = append(stmt.Names, p.cur.Literal)
+			if p.peek.Type != token.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+		p.expectPeek(token.RBRACE)
+		p.expectPeek(token.FROM)
+	}
+
+	p.expectPeek(token.STRING_LITERAL)
+	stmt.Path = p.cur.Literal
+
+	p.expectPeek(token.SEMICOLON)
+
+	p.nextToken() // advance the parser to the next token
+
+	return stmt
+}
+
 // parseClass parses a Class statement and returns an AST ClassStatement node
 func (p *Parser) parseClass() ast.Statement {
 	stmt := &ast.ClassStatement{Token: p.cur} // create a new ClassStatement node
@@ -127,11 +209,24 @@ func (p *Parser) parseClass() ast.Statement {
 	}
 	stmt.Name = p.cur.Literal // set the Name field of the ClassStatement node
 
+	// Parent contracts and interfaces: class contract Token is Ownable, IToken { ... }
+	if p.peek.Is(token.IS) {
+		p.nextToken()
+		for p.expectPeek(token.IDENT) {
+			stmt.Parents = append(stmt.Parents, p.cur.Literal)
+			if p.peek.Type != token.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+	}
+
 	p.expectPeek(token.LBRACE)
 
 	stmt.Body = []ast.Statement{}                                 // initialize the Body slice
 	for p.peek.Type != token.RBRACE && p.peek.Type != token.EOF { // loop until the end of the class
 		p.nextToken() // advance the parser to the next token
+		i, n := p.beginSpan(), len(stmt.Body)
 
 		public := false
 		if p.cur.Type == token.PUB {
@@ -139,6 +234,13 @@ func (p *Parser) parseClass() ast.Statement {
 			p.nextToken()
 		}
 
+		// const and immutable go before the type: pub const uint64 fee: 2;
+		kind := ""
+		if p.cur.Is(token.CONST) || p.cur.Is(token.IMMUTABLE) {
+			kind = p.cur.Literal
+			p.nextToken()
+		}
+
 		switch p.cur.Type {
 		case token.ENUM:
 			enumStmt := p.parseEnum()
@@ -152,30 +254,52 @@ func (p *Parser) parseClass() ast.Statement {
 		case token.FUNC:
 			funcStmt := p.parseFunc(public)
 			stmt.Body = append(stmt.Body, funcStmt)
+		case token.IDENT:
+			if p.cur.Is(token.ERROR) && p.peek.Type == token.IDENT { // error Name(...) declares a custom error
+				errorStmt := p.parseError()
+				stmt.Body = append(stmt.Body, errorStmt)
+			} else if kind != "" {
+				p.addError(p.cur, "expected a type after "+kind+", got "+string(p.cur.Type))
+			}
+		case token.CONSTRUCTOR:
+			constructorStmt := p.parseConstructor()
+			stmt.Body = append(stmt.Body, constructorStmt)
+		case token.MODIFIER:
+			modifierStmt := p.parseModifier()
+			stmt.Body = append(stmt.Body, modifierStmt)
 		default:
 			switch p.cur.Type {
 			case token.UINT64:
-				stmt.Body = append(stmt.Body, p.parseVariables(public))
+				stmt.Body = append(stmt.Body, p.parseVariables(public, kind))
 			case token.ADDRESS:
-				stmt.Body = append(stmt.Body, p.parseVariables(public))
+				stmt.Body = append(stmt.Body, p.parseVariables(public, kind))
 			case token.BOOL:
-				stmt.Body = append(stmt.Body, p.parseVariables(public))
+				stmt.Body = append(stmt.Body, p.parseVariables(public, kind))
 			case token.BYTE:
-				stmt.Body = append(stmt.Body, p.parseVariables(public))
+				stmt.Body = append(stmt.Body, p.parseVariables(public, kind))
 			case token.HASH:
-				stmt.Body = append(stmt.Body, p.parseVariables(public))
+				stmt.Body = append(stmt.Body, p.parseVariables(public, kind))
 			case token.STRING:
-				stmt.Body = append(stmt.Body, p.parseVariables(public))
+				stmt.Body = append(stmt.Body, p.parseVariables(public, kind))
+			default:
+				if kind != "" {
+					p.addError(p.cur, "expected a type after "+kind+", got "+string(p.cur.Type))
+				}
 			}
 		}
 
+		if len(stmt.Body) > n {
+			p.endSpan(i, stmt.Body[n])
+		} else {
+			p.endSpan(i, nil)
+		}
 	}
 
 	return stmt // return the ClassStatement node
 }
 
-func (p *Parser) parseVariables(public bool) ast.Statement {
-	stmt := &ast.VariableStatement{Token: p.cur}
+func (p *Parser) parseVariables(public bool, kind string) ast.Statement {
+	stmt := &ast.VariableStatement{Token: p.cur, Kind: kind}
 	p.nextToken()
 
 	stmt.Public = public
@@ -195,6 +319,7 @@ func (p *Parser) parseVariables(public bool) ast.Statement {
 		varStmt := &ast.VariableStatementNonInitializer{Token: stmt.Token}
 		varStmt.Name = stmt.Name
 		varStmt.Public = public
+		varStmt.Kind = kind
 
 		p.expectPeek(token.SEMICOLON)
 
@@ -338,7 +463,7 @@ func (p *Parser) parseStruct() ast.Statement {
 
 // parseStorage parses a Storage statement and returns an AST StorageDeclaration node
 func (p *Parser) parseStorage(public bool) ast.Statement {
-	fmt.Printf("========= PREPARING STORAGE %s ========\n", p.cur.Literal)
+	p.tracef("========= PREPARING STORAGE %s ========", p.cur.Literal)
 
 	stmt := &ast.StorageDeclaration{Token: p.cur} // create a new StorageDeclaration node, storing the current token (e.g., 'storage')
 	p.nextToken()                                 // advance to the next token (should be the storage name)
@@ -360,7 +485,7 @@ func (p *Parser) parseStorage(public bool) ast.Statement {
 			key := ast.Key{Token: p.cur} // create a new Key node
 			key.Name = p.cur.Literal     // set the parameter name
 
-			fmt.Println(key)
+			p.tracef("storage key: %s", key.Name)
 
 			p.expectPeek(token.COLON)
 
@@ -454,7 +579,7 @@ func (p *Parser) parseFunc(public bool) ast.Statement {
 
 	stmt.Name = p.cur.Literal
 
-	fmt.Printf("========= PREPARING FUNC %s ========\n", stmt.Name)
+	p.tracef("========= PREPARING FUNC %s ========", stmt.Name)
 
 	p.expectPeek(token.LPAREN)
 
@@ -518,11 +643,35 @@ func (p *Parser) parseFunc(public bool) ast.Statement {
 				p.peekError(token.IDENT)
 				return nil
 			}
+		} else if token.IsReserved(p.cur.Literal) {
+			p.addError(p.cur, "expected identifier, got keyword "+p.cur.Literal)
+			return nil
 		}
 	}
 
 	p.nextToken()
 
+	// State mutability goes right after the parameters: balance(account: address) view: uint64
+	if p.peek.Is(token.VIEW) || p.peek.Is(token.PURE) || p.peek.Type == token.PAYABLE {
+		p.nextToken()
+		stmt.Mutability = p.cur.Literal
+	}
+
+	// Modifiers applied between the parameters and the return type: withdraw() onlyOwner: void
+	for p.peek.Type == token.IDENT || p.peek.Type == token.OVERRIDE {
+		p.nextToken()
+		if p.cur.Type == token.OVERRIDE { // transfer(...) override: bool replaces an inherited function
+			stmt.Override = true
+			continue
+		}
+		modifier := ast.ModifierInvocation{Token: p.cur, Name: p.cur.Literal}
+		if p.peek.Type == token.LPAREN {
+			p.nextToken()
+			modifier.Arguments = p.parseCallArguments()
+		}
+		stmt.Modifiers = append(stmt.Modifiers, modifier)
+	}
+
 	p.expectPeek(token.COLON)
 
 	p.nextToken()
@@ -559,28 +708,263 @@ func (p *Parser) parseFunc(public bool) ast.Statement {
 		return nil
 	}
 
+	// Interface functions have no body: pub func transfer(to: address, amount: uint64): bool;
+	if p.peek.Type == token.SEMICOLON {
+		p.nextToken()
+		return stmt
+	}
+
 	p.expectPeek(token.LBRACE)
 
-	stmt.Body = []ast.Statement{}
+	stmt.Body = p.parseBlock()
+
+	p.tracef("========= FUNC DONE %s ========", stmt.Name)
+
+	return stmt
+}
+
+// parseConstructor parses a constructor(...) { ... } member of a class
+func (p *Parser) parseConstructor() ast.Statement {
+	stmt := &ast.ConstructorStatement{Token: p.cur}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	params, ok := p.parseParams()
+	if !ok {
+		return nil
+	}
+	stmt.Params = params
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlock()
+
+	return stmt
+}
+
+// parseModifier parses a modifier declaration such as
+// modifier onlyOwner() { check(...); _; }
+func (p *Parser) parseModifier() ast.Statement {
+	stmt := &ast.ModifierStatement{Token: p.cur}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = p.cur.Literal
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	params, ok := p.parseParams()
+	if !ok {
+		return nil
+	}
+	stmt.Params = params
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlock()
+
+	return stmt
+}
+
+// parseBlock parses the statements of a function or constructor body. It expects
+// the current token to be '{' and leaves the parser on the closing '}'.
+func (p *Parser) parseBlock() []ast.Statement {
+	body := []ast.Statement{}
 	for p.peek.Type != token.RBRACE && p.peek.Type != token.EOF {
 		p.nextToken()
+		i, n := p.beginSpan(), len(body)
 
 		switch p.cur.Type {
 		case token.RETURN:
-			stmt.Body = append(stmt.Body, p.parseReturn())
+			body = append(body, p.parseReturn())
 		case token.NEW:
-			stmt.Body = append(stmt.Body, p.parseNew())
+			body = append(body, p.parseNew())
 		case token.DELETE:
-			stmt.Body = append(stmt.Body, p.parseDelete())
+			body = append(body, p.parseDelete())
+		case token.IDENT:
+			if p.cur.Is(token.REVERT) && p.peek.Type == token.IDENT { // revert Name(...) raises a custom error
+				body = append(body, p.parseRevert())
+				break
+			}
+			if p.cur.Literal == "_" && p.peek.Type == token.SEMICOLON { // placeholder for the modified function body
+				body = append(body, &ast.PlaceholderStatement{Token: p.cur})
+				p.nextToken()
+				break
+			}
+			if p.peek.Type == token.COLON {
+				body = append(body, p.parseAssign())
+				break
+			}
+			body = append(body, p.parseExpressionStatement())
 		default:
-			stmt.Body = append(stmt.Body, p.parseExpressionStatement())
+			body = append(body, p.parseExpressionStatement())
+		}
+
+		if len(body) > n {
+			p.endSpan(i, body[n])
+		} else {
+			p.endSpan(i, nil)
+		}
+	}
+
+	p.nextToken()
+
+	return body
+}
+
+// parseError parses a custom error declaration such as
+// error InsufficientBalance(needed: uint64, available: uint64);
+func (p *Parser) parseError() ast.Statement {
+	stmt := &ast.ErrorStatement{Token: p.cur}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = p.cur.Literal
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	params, ok := p.parseParams()
+	if !ok {
+		return nil
+	}
+	stmt.Params = params
+
+	p.expectPeek(token.SEMICOLON)
+
+	return stmt
+}
+
+// parseRevert parses a revert statement such as revert InsufficientBalance(x, y);
+func (p *Parser) parseRevert() ast.Statement {
+	stmt := &ast.RevertStatement{Token: p.cur}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Error = p.parseErrorCall()
+
+	p.expectPeek(token.SEMICOLON)
+
+	return stmt
+}
+
+// parseErrorCall parses Name(args) for a custom error, leaving the parser on the closing ')'
+func (p *Parser) parseErrorCall() *ast.ErrorCallExpression {
+	expr := &ast.ErrorCallExpression{Token: p.cur, Name: p.cur.Literal}
+
+	if !p.expectPeek(token.LPAREN) {
+		return expr
+	}
+
+	expr.Arguments = p.parseCallArguments()
+
+	return expr
+}
+
+// parseCallArguments parses a comma separated list of expressions. It expects the
+// current token to be '(' and leaves the parser on the closing ')'.
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if p.peek.Type == token.RPAREN {
+		p.nextToken()
+		return args
+	}
+
+	p.nextToken()
+	for {
+		args = append(args, p.parseExpression()) // leaves the parser on the token after the argument
+		if p.cur.Type != token.COMMA {
+			break
+		}
+		p.nextToken()
+	}
+
+	if p.cur.Type != token.RPAREN {
+		p.addError(p.cur, "expected ) after call arguments, got "+string(p.cur.Type))
+	}
+
+	return args
+}
+
+// parseParams parses a list of typed parameters such as (a: uint64, b: address).
+// It expects the current token to be '(' and leaves the parser on the closing ')'.
+func (p *Parser) parseParams() ([]ast.Key, bool) {
+	params := []ast.Key{}
+
+	for p.peek.Type != token.RPAREN && p.peek.Type != token.EOF {
+		if len(params) > 0 && !p.expectPeek(token.COMMA) { // parameters are separated by commas
+			return nil, false
+		}
+		if !p.expectPeek(token.IDENT) {
+			return nil, false
+		}
+
+		key := ast.Key{Token: p.cur, Name: p.cur.Literal}
+
+		if !p.expectPeek(token.COLON) {
+			return nil, false
+		}
+		p.nextToken()
+
+		paramType, ok := p.parseType()
+		if !ok {
+			p.peekError(token.IDENT)
+			return nil, false
 		}
+		key.Type = paramType
+		params = append(params, key)
 	}
 
 	p.nextToken()
 
-	fmt.Printf("========= FUNC DONE %s ========\n", stmt.Name)
+	return params, true
+}
+
+// parseType returns the type name for the current token, including []T arrays
+func (p *Parser) parseType() (string, bool) {
+	switch p.cur.Type {
+	case token.UINT64:
+		return "uint64", true
+	case token.ADDRESS:
+		return "address", true
+	case token.BOOL:
+		return "bool", true
+	case token.BYTE:
+		return "byte", true
+	case token.HASH:
+		return "hash", true
+	case token.STRING:
+		return "string", true
+	case token.LBRACKET:
+		if !p.expectPeek(token.RBRACKET) {
+			return "", false
+		}
+		p.nextToken()
+		return fmt.Sprintf("[]%s", p.cur.Literal), true
+	}
+	return "", false
+}
 
+// parseAssign parses the assignment of a contract variable such as count: count + 1;
+func (p *Parser) parseAssign() ast.Statement {
+	stmt := &ast.AssignStatement{Token: p.cur, Name: p.cur.Literal}
+	p.nextToken()
+	p.nextToken()
+	stmt.Value = p.parseExpression()
 	return stmt
 }
 
@@ -592,7 +976,7 @@ func (p *Parser) parseExpressionStatement() ast.Statement {
 }
 
 func (p *Parser) parseDelete() ast.Statement {
-	fmt.Printf("Parsing delete statement: %s \n", p.cur.Literal)
+	p.tracef("Parsing delete statement: %s", p.cur.Literal)
 
 	stmt := &ast.DeleteStatement{Token: p.cur}
 	p.nextToken()
@@ -623,7 +1007,7 @@ func (p *Parser) parseDelete() ast.Statement {
 }
 
 func (p *Parser) parseNew() ast.Statement {
-	fmt.Printf("Parsing new statement: %s \n", p.cur.Literal)
+	p.tracef("Parsing new statement: %s", p.cur.Literal)
 
 	stmt := &ast.NewStatement{Token: p.cur}
 	p.nextToken()
@@ -661,7 +1045,7 @@ func (p *Parser) parseReturn() ast.Statement {
 	stmt := &ast.ReturnStatement{Token: p.cur}
 	p.nextToken()
 
-	fmt.Printf("Parsing return statement: %s \n", p.cur.Literal)
+	p.tracef("Parsing return statement: %s", p.cur.Literal)
 
 	stmt.Value = p.parseExpression()
 
@@ -669,12 +1053,18 @@ func (p *Parser) parseReturn() ast.Statement {
 }
 
 func (p *Parser) parseExpression() ast.Expression {
-	fmt.Printf("Parsing expresion: %s \n", p.cur.Literal)
+	p.tracef("Parsing expresion: %s", p.cur.Literal)
 
 	var left ast.Expression
 	switch p.cur.Type {
 	case token.IDENT:
-		if p.peek.Type != token.LPAREN {
+		if p.cur.Is(token.CREATE) && p.peek.Type == token.IDENT { // create Name(args) deploys a contract
+			left = p.parseCreateExpression()
+		} else if p.peek.Type == token.LPAREN && token.IsBuiltin(p.cur.Literal) {
+			left = p.parseCallExpression()
+		} else if p.peek.Type == token.DOT {
+			left = p.parseMemberExpression()
+		} else if p.peek.Type != token.LPAREN {
 			left = p.parseIdentifier()
 		} else {
 			left = p.parseStorageStatement()
@@ -687,18 +1077,18 @@ func (p *Parser) parseExpression() ast.Expression {
 		p.nextToken()
 		left = p.parseExpression()
 	case token.INT:
-		fmt.Printf("Parsing integer literal: %s \n", p.cur.Literal)
+		p.tracef("Parsing integer literal: %s", p.cur.Literal)
 		left = p.parseIntegerLiteral()
 	case token.UINT64:
 		left = p.parseConstExpression()
 	case token.STRING_LITERAL:
-		fmt.Printf("Parsing string literal: %s \n", p.cur.Literal)
+		p.tracef("Parsing string literal: %s", p.cur.Literal)
 		left = p.parseStringLiteral()
 	case token.BOOL_LITERAL:
-		fmt.Printf("Parsing bool literal: %s \n", p.cur.Literal)
+		p.tracef("Parsing bool literal: %s", p.cur.Literal)
 		left = p.parseBoolLiteral()
 	case token.HASH_LITERAL:
-		fmt.Printf("Parsing hash literal: %s \n", p.cur.Literal)
+		p.tracef("Parsing hash literal: %s", p.cur.Literal)
 		left = p.parseHashLiteral()
 	case token.LBRACKET:
 		left = p.parseArrayLiteral()
@@ -714,7 +1104,10 @@ func (p *Parser) parseExpression() ast.Expression {
 		left = p.parseConstExpression()
 	case token.ADDRESS_LITERAL:
 		left = p.parseAddressLiteral()
-
+	default:
+		if token.IsReserved(p.cur.Literal) {
+			p.addError(p.cur, "expected identifier, got keyword "+p.cur.Literal)
+		}
 	}
 
 	if p.cur.Type != token.SEMICOLON {
@@ -757,6 +1150,13 @@ func (p *Parser) parseErrValue() ast.Expression {
 	p.expectPeek(token.COLON)
 	p.nextToken()
 
+	// err: Name(args) raises a custom error instead of a string message
+	if p.cur.Type == token.IDENT && p.peek.Type == token.LPAREN {
+		stmt.Value = p.parseErrorCall()
+		p.nextToken() // step past the closing ')' like parseExpression does
+		return stmt
+	}
+
 	if p.cur.Type != token.STRING_LITERAL {
 		p.peekError(token.STRING_LITERAL)
 	}
@@ -788,7 +1188,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	stmt := &ast.HashLiteral{Token: token.Token{Type: token.HASH, Literal: "hash"}}
 	stmt.Value = p.cur.Literal
 
-	if p.peek.Type != token.COMMA {
+	if p.peek.Type == token.SEMICOLON {
 		p.expectPeek(token.SEMICOLON)
 	}
 
@@ -796,7 +1196,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 }
 
 func (p *Parser) parseArrayLiteral() ast.Expression {
-	fmt.Printf("Parsing array literal: %s \n", p.cur.Literal)
+	p.tracef("Parsing array literal: %s", p.cur.Literal)
 
 	stmt := &ast.ArrayLiteral{Token: token.Token{Type: token.ARRAY, Literal: "array"}}
 
@@ -823,8 +1223,9 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	}
 	p.nextToken()
 
-	p.expectPeek(token.SEMICOLON)
-
+	if p.peek.Type == token.SEMICOLON {
+		p.expectPeek(token.SEMICOLON)
+	}
 	return stmt
 }
 
@@ -851,7 +1252,7 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 }
 
 func (p *Parser) parseConstExpression() ast.Expression {
-	fmt.Printf("Parsing const expression: %s \n", p.cur.Literal)
+	p.tracef("Parsing const expression: %s", p.cur.Literal)
 
 	stmt := &ast.ConstExpression{Token: p.cur}
 	p.nextToken()
@@ -873,7 +1274,7 @@ func (p *Parser) parseConstExpression() ast.Expression {
 }
 
 func (p *Parser) parseStorageStatement() ast.Expression {
-	fmt.Printf("Parsing storage statement: %s \n", p.cur.Literal)
+	p.tracef("Parsing storage statement: %s", p.cur.Literal)
 
 	stmt := &ast.StorageStatement{Token: token.Token{Type: token.STORAGE, Literal: "storage"}}
 
@@ -885,27 +1286,22 @@ func (p *Parser) parseStorageStatement() ast.Expression {
 
 	p.expectPeek(token.LPAREN)
 
-	for p.peek.Type != token.RPAREN && p.peek.Type != token.EOF {
-		p.nextToken()
+	stmt.Params = p.parseCallArguments() // storage keys may be any expression, e.g. owners(caller())
 
-		if p.cur.Type == token.IDENT {
-			param := ast.Identifier{Token: p.cur, Value: p.cur.Literal}
-			stmt.Params = append(stmt.Params, param)
-			if p.peek.Type == token.COMMA {
-				p.nextToken()
-			}
-		}
+	// Name(addr).method(args) is a call to another contract through an interface
+	if p.peek.Type == token.DOT {
+		return p.parseExternalCall(stmt.Name, stmt.Params)
 	}
 
-	p.nextToken()
-
 	if p.peek.Type != token.COLON {
-		fmt.Printf("Parsing storage access statement: %s \n", p.cur.Literal)
+		p.tracef("Parsing storage access statement: %s", p.cur.Literal)
 		access_storage := &ast.StorageAccessStatement{Token: token.Token{Type: token.STORAGE, Literal: "storage"}}
 		access_storage.Name = stmt.Name
 		access_storage.Params = stmt.Params
 
-		p.expectPeek(token.SEMICOLON)
+		if p.peek.Type == token.SEMICOLON { // storage reads may also appear inside larger expressions
+			p.nextToken()
+		}
 
 		return access_storage
 	}
@@ -926,18 +1322,87 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	value, _ := strconv.ParseInt(p.cur.Literal, 10, 64) // parse the literal value as an integer
 	stmt.Value = uint64(value)
 
-	if p.peek.Type == token.RPAREN {
-		p.nextToken()
-	}
-
+	// A closing ')' is left to the caller: it ends call arguments such as E(1)
+	// as well as parenthesised expressions such as (1) + 2.
 	if p.peek.Type == token.SEMICOLON {
 		p.expectPeek(token.SEMICOLON)
 	}
 	return stmt
 }
 
+// parseCallExpression parses a builtin call such as balanceOf(addr), leaving the parser on the closing ')'
+func (p *Parser) parseCallExpression() ast.Expression {
+	expr := &ast.CallExpression{Token: p.cur, Function: &ast.Identifier{Token: p.cur, Value: p.cur.Literal}}
+
+	p.expectPeek(token.LPAREN)
+
+	expr.Arguments = p.parseCallArguments()
+
+	return expr
+}
+
+// parseExternalCall parses the .method(args) part of Interface(addr).method(args).
+// It expects the current token to be the ')' after the address and leaves the
+// parser on the closing ')' of the call.
+func (p *Parser) parseExternalCall(iface string, address []ast.Expression) ast.Expression {
+	expr := &ast.ExternalCallExpression{Token: token.Token{Type: token.IDENT, Literal: iface}, Interface: iface}
+
+	if len(address) != 1 {
+		p.addError(p.cur, "expected a single address in "+iface+"(...)")
+		return nil
+	}
+	expr.Address = address[0]
+
+	p.nextToken() // cur is now '.'
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	expr.Method = p.cur.Literal
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	expr.Arguments = p.parseCallArguments()
+
+	return expr
+}
+
+// parseCreateExpression parses create Name(args), leaving the parser on the closing ')'
+func (p *Parser) parseCreateExpression() ast.Expression {
+	expr := &ast.CreateExpression{Token: p.cur}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	expr.Contract = p.cur.Literal
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	expr.Arguments = p.parseCallArguments()
+
+	return expr
+}
+
+// parseMemberExpression parses object.member, leaving the parser on the member name
+func (p *Parser) parseMemberExpression() ast.Expression {
+	expr := &ast.MemberExpression{Token: p.cur, Object: &ast.Identifier{Token: p.cur, Value: p.cur.Literal}}
+
+	p.nextToken() // skip the object name, cur is now '.'
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	expr.Member = p.cur.Literal
+
+	return expr
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
-	fmt.Printf("Parsing identifier: %s \n", p.cur.Literal)
+	p.tracef("Parsing identifier: %s", p.cur.Literal)
 	stmt := &ast.Identifier{Token: p.cur, Value: p.cur.Literal}
 	if p.peek.Type == token.SEMICOLON {
 		p.expectPeek(token.SEMICOLON)
@@ -947,7 +1412,7 @@ func (p *Parser) parseIdentifier() ast.Expression {
 }
 
 func (p *Parser) parseBinaryExpression(left ast.Expression) ast.Expression {
-	fmt.Printf("Parsing binary expression: %s \n", p.cur.Literal)
+	p.tracef("Parsing binary expression: %s", p.cur.Literal)
 
 	expr := &ast.BinaryExpression{
 		Token:    p.cur,
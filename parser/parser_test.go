@@ -2,10 +2,15 @@ package parser
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/polarysfoundation/ryot/ast"
 	"github.com/polarysfoundation/ryot/lexer"
+	"github.com/polarysfoundation/ryot/token"
 )
 
 func TestParse_Program(t *testing.T) {
@@ -236,3 +241,724 @@ func TestParse_FuncWithReturn(t *testing.T) {
 	fmt.Println(program.Statements[1].(*ast.ClassStatement).Body[4].(*ast.FuncStatement).Body[0].(*ast.ReturnStatement).Value)
 	fmt.Println(program.Statements[1].(*ast.ClassStatement).Body[7].(*ast.FuncStatement).Body[0].(*ast.ReturnStatement).Value)
 }
+
+func TestParse_CustomError(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Vault {
+		error InsufficientBalance(needed: uint64, available: uint64);
+
+		pub func withdraw(amount: uint64, current: uint64): void {
+			check(current >= amount, err: InsufficientBalance(amount, current));
+			revert InsufficientBalance(amount, 0);
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	body := program.Statements[1].(*ast.ClassStatement).Body
+
+	decl, ok := body[0].(*ast.ErrorStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ErrorStatement, got %T", body[0])
+	}
+	if decl.Name != "InsufficientBalance" || len(decl.Params) != 2 || decl.Params[1].Type != "uint64" {
+		t.Fatalf("unexpected error declaration: %+v", decl)
+	}
+
+	fn := body[1].(*ast.FuncStatement)
+
+	check := fn.Body[0].(*ast.ExpressionStatement).Expression.(*ast.ErrLiteral)
+	errCall, ok := check.Return.(*ast.ErrValue).Value.(*ast.ErrorCallExpression)
+	if !ok {
+		t.Fatalf("expected *ast.ErrorCallExpression, got %T", check.Return.(*ast.ErrValue).Value)
+	}
+	if errCall.String() != "InsufficientBalance(amount, current)" {
+		t.Fatalf("unexpected error call: %s", errCall)
+	}
+
+	revert, ok := fn.Body[1].(*ast.RevertStatement)
+	if !ok {
+		t.Fatalf("expected *ast.RevertStatement, got %T", fn.Body[1])
+	}
+	if revert.String() != "revert InsufficientBalance(amount, 0)" {
+		t.Fatalf("unexpected revert: %s", revert)
+	}
+}
+
+func TestParse_MemberCallsShadowBuiltins(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Base {
+		pub func transfer(to: address, amount: uint64): bool { return true; }
+	}
+	class contract Ledger is Base {
+		pub func send(to: address): bool { return transfer(to, value(1)); }
+		pub func pay(to: address): void { check(balanceOf(to) > 0, err: "empty"); }
+		pub storage value(k: uint64): uint64;
+	}
+	`
+	p := New(lexer.New(input))
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	body := program.Statements[2].(*ast.ClassStatement).Body
+	send := body[0].(*ast.FuncStatement).Body[0].(*ast.ReturnStatement).Value
+	access, ok := send.(*ast.StorageAccessStatement)
+	if !ok || access.Name != "transfer" {
+		t.Fatalf("expected the inherited transfer to be a member call, got %T", send)
+	}
+	if arg, ok := access.Params[1].(*ast.StorageAccessStatement); !ok || arg.Name != "value" {
+		t.Fatalf("expected the value storage to be a member call, got %T", access.Params[1])
+	}
+
+	check := body[1].(*ast.FuncStatement).Body[0].(*ast.ExpressionStatement).Expression.(*ast.ErrLiteral)
+	if _, ok := check.Value.(*ast.BinaryExpression).Left.(*ast.CallExpression); !ok {
+		t.Fatalf("expected balanceOf to stay a builtin call")
+	}
+}
+
+func TestParse_MalformedErrorParams(t *testing.T) {
+	inputs := []string{
+		`pragma: "1.0.0";
+		class contract Vault {
+			error E(a: uint64 b: uint64);
+		}`,
+		`pragma: "1.0.0";
+		class contract Vault {
+			error E(a: uint64, 5);
+		}`,
+	}
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("expected parser errors for %q", input)
+		}
+	}
+}
+
+func TestParse_ParenthesisedLiterals(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Vault {
+		error E(code: uint64);
+
+		pub func a(): uint64 {
+			return (5);
+		}
+
+		pub func b(): uint64 {
+			return (1) + 2;
+		}
+
+		pub func c(): void {
+			revert E(1);
+		}
+
+		pub func d(): uint64 {
+			return balanceOf(1);
+		}
+	}
+	`
+	p := New(lexer.New(input))
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	body := program.Statements[1].(*ast.ClassStatement).Body
+	values := []string{"5", "(1 + 2)", "", "balanceOf(1)"}
+	for i, want := range values {
+		stmt := body[i+1].(*ast.FuncStatement).Body[0]
+		got := stmt.String()
+		if ret, ok := stmt.(*ast.ReturnStatement); ok {
+			got = ret.Value.String()
+		} else {
+			want = "revert E(1)"
+		}
+		if got != want {
+			t.Fatalf("function %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestParse_Constructor(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Counter {
+		pub storage owners(account: address): bool;
+
+		constructor(owner: address, start: uint64) {
+			owners(owner): true;
+		}
+
+		pub func current(): uint64 {
+			return 1;
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	body := program.Statements[1].(*ast.ClassStatement).Body
+	if len(body) != 3 {
+		t.Fatalf("expected 3 class members, got %d", len(body))
+	}
+
+	constructor, ok := body[1].(*ast.ConstructorStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ConstructorStatement, got %T", body[1])
+	}
+	if len(constructor.Params) != 2 || constructor.Params[0].Type != "address" {
+		t.Fatalf("unexpected constructor params: %+v", constructor.Params)
+	}
+	if len(constructor.Body) != 1 {
+		t.Fatalf("expected 1 constructor statement, got %d", len(constructor.Body))
+	}
+}
+
+func TestParse_Modifier(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Treasury {
+		modifier onlyOwner() {
+			check(owner(caller), err: "Unauthorized");
+			_;
+		}
+
+		pub func withdraw(amount: uint64) onlyOwner underLimit(1, amount): void {
+			limit(1): amount;
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	body := program.Statements[1].(*ast.ClassStatement).Body
+
+	modifier, ok := body[0].(*ast.ModifierStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ModifierStatement, got %T", body[0])
+	}
+	if len(modifier.Body) != 2 {
+		t.Fatalf("expected 2 modifier statements, got %d", len(modifier.Body))
+	}
+	if _, ok := modifier.Body[1].(*ast.PlaceholderStatement); !ok {
+		t.Fatalf("expected *ast.PlaceholderStatement, got %T", modifier.Body[1])
+	}
+
+	fn := body[1].(*ast.FuncStatement)
+	if len(fn.Modifiers) != 2 {
+		t.Fatalf("expected 2 modifiers, got %d", len(fn.Modifiers))
+	}
+	if fn.Modifiers[0].String() != "onlyOwner" || fn.Modifiers[1].String() != "underLimit(1, amount)" {
+		t.Fatalf("unexpected modifiers: %s, %s", fn.Modifiers[0].String(), fn.Modifiers[1].String())
+	}
+	if fn.ReturnType.Type != "void" {
+		t.Fatalf("expected void return type, got %s", fn.ReturnType.Type)
+	}
+}
+
+func TestParse_ContextBuiltins(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Context {
+		pub func touch(): void {
+			lastSeen(caller()): block.timestamp;
+			return balanceOf(self());
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	fn := program.Statements[1].(*ast.ClassStatement).Body[0].(*ast.FuncStatement)
+
+	store := fn.Body[0].(*ast.ExpressionStatement).Expression.(*ast.StorageStatement)
+	if _, ok := store.Params[0].(*ast.CallExpression); !ok {
+		t.Fatalf("expected storage key to be *ast.CallExpression, got %T", store.Params[0])
+	}
+	member, ok := store.Value.(*ast.MemberExpression)
+	if !ok || member.String() != "block.timestamp" {
+		t.Fatalf("expected block.timestamp, got %v", store.Value)
+	}
+
+	call, ok := fn.Body[1].(*ast.ReturnStatement).Value.(*ast.CallExpression)
+	if !ok || call.String() != "balanceOf(self())" {
+		t.Fatalf("expected balanceOf(self()), got %v", fn.Body[1].(*ast.ReturnStatement).Value)
+	}
+}
+
+func TestParse_InterfaceCall(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class interface IToken {
+		pub func transfer(to: address, amount: uint64): bool;
+	}
+
+	class contract Wallet {
+		pub func pay(token: address, to: address): bool {
+			return IToken(token).transfer(to, 10);
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	iface := program.Statements[1].(*ast.ClassStatement)
+	if !iface.IsInterface {
+		t.Fatalf("expected IToken to be an interface")
+	}
+	if fn := iface.Body[0].(*ast.FuncStatement); fn.Body != nil || fn.ReturnType.Type != "bool" {
+		t.Fatalf("expected a bodyless bool function, got %+v", fn)
+	}
+
+	fn := program.Statements[2].(*ast.ClassStatement).Body[0].(*ast.FuncStatement)
+	call, ok := fn.Body[0].(*ast.ReturnStatement).Value.(*ast.ExternalCallExpression)
+	if !ok {
+		t.Fatalf("expected *ast.ExternalCallExpression, got %T", fn.Body[0].(*ast.ReturnStatement).Value)
+	}
+	if call.String() != "IToken(token).transfer(to, 10)" {
+		t.Fatalf("unexpected external call: %s", call)
+	}
+}
+
+func TestParse_Inheritance(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Token is Ownable, IToken {
+		pub func transfer(to: address, amount: uint64) override: bool {
+			return true;
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	class := program.Statements[1].(*ast.ClassStatement)
+	if len(class.Parents) != 2 || class.Parents[0] != "Ownable" || class.Parents[1] != "IToken" {
+		t.Fatalf("unexpected parents: %v", class.Parents)
+	}
+	fn := class.Body[0].(*ast.FuncStatement)
+	if !fn.Override || fn.ReturnType.Type != "bool" {
+		t.Fatalf("expected an override bool function, got %+v", fn)
+	}
+}
+
+func TestParse_Create(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Factory {
+		pub func open(owner: address): address {
+			return create Vault(owner, 100);
+		}
+
+		pub func close(to: address): void {
+			selfdestruct(to);
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	class := program.Statements[1].(*ast.ClassStatement)
+	ret := class.Body[0].(*ast.FuncStatement).Body[0].(*ast.ReturnStatement)
+	create, ok := ret.Value.(*ast.CreateExpression)
+	if !ok {
+		t.Fatalf("expected *ast.CreateExpression, got %T", ret.Value)
+	}
+	if create.String() != "create Vault(owner, 100)" {
+		t.Fatalf("unexpected create expression: %s", create)
+	}
+
+	stmt := class.Body[1].(*ast.FuncStatement).Body[0].(*ast.ExpressionStatement)
+	if call, ok := stmt.Expression.(*ast.CallExpression); !ok || call.String() != "selfdestruct(to)" {
+		t.Fatalf("expected selfdestruct(to), got %s", stmt.Expression)
+	}
+}
+
+func TestParse_Import(t *testing.T) {
+	input := `pragma: "1.0.0";
+	import "./ownable.ry";
+	import { IToken, IERC } from "lib/interfaces.ry";
+
+	class contract Token is Ownable, IToken {
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	all := program.Statements[1].(*ast.ImportStatement)
+	if all.Path != "./ownable.ry" || len(all.Names) != 0 {
+		t.Fatalf("unexpected import: %s", all)
+	}
+	named := program.Statements[2].(*ast.ImportStatement)
+	if named.String() != `import { IToken, IERC } from "lib/interfaces.ry";` {
+		t.Fatalf("unexpected import: %s", named)
+	}
+	if _, ok := program.Statements[3].(*ast.ClassStatement); !ok {
+		t.Fatalf("expected the class after the imports, got %T", program.Statements[3])
+	}
+}
+
+func TestParse_ContextualKeywords(t *testing.T) {
+	input := `pragma: "1.0.0";
+	import { from } from "./from.ry";
+
+	class contract Vault is from {
+		storage balances(address): uint64;
+
+		pub func transfer(from: address, create: uint64) view: uint64 {
+			return balances(from) + create;
+		}
+	}
+	`
+	p := New(lexer.New(input))
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	if imp := program.Statements[1].(*ast.ImportStatement); len(imp.Names) != 1 || imp.Names[0] != "from" {
+		t.Fatalf("unexpected import: %s", imp)
+	}
+	class := program.Statements[2].(*ast.ClassStatement)
+	if len(class.Parents) != 1 || class.Parents[0] != "from" {
+		t.Fatalf("unexpected parents %v", class.Parents)
+	}
+	fn := class.Body[1].(*ast.FuncStatement)
+	if len(fn.Params) != 2 || fn.Params[0].Name != "from" || fn.Params[1].Name != "create" || fn.Mutability != "view" {
+		t.Fatalf("unexpected function %s", fn)
+	}
+	if ret := fn.Body[0].(*ast.ReturnStatement); ret.Value.String() != "(balances(from) + create)" {
+		t.Fatalf("unexpected return value %s", ret.Value)
+	}
+
+	inputs := map[string]string{
+		"parameter":  `pub func f(return: uint64): void { }`,
+		"expression": `pub func f(): uint64 { return storage; }`,
+	}
+	for name, member := range inputs {
+		p := New(lexer.New("pragma: \"1.0.0\";\nclass contract Vault {\n" + member + "\n}"))
+		p.ParseProgram()
+		if errs := p.Errors(); len(errs) == 0 || !strings.Contains(errs[0], "expected identifier, got keyword") {
+			t.Fatalf("%s: expected a keyword error, got %v", name, errs)
+		}
+	}
+}
+
+func TestParse_Tracer(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Counter {
+		pub storage counts(id: uint64): uint64;
+
+		pub func get(id: uint64): uint64 {
+			return counts(id);
+		}
+	}
+	`
+
+	events := []string{}
+	tracer := TracerFunc(func(format string, args ...interface{}) {
+		events = append(events, fmt.Sprintf(format, args...))
+	})
+
+	p := New(lexer.New(input), WithTracer(tracer))
+	p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	if len(events) == 0 || !strings.HasPrefix(events[len(events)-1], "program: ") {
+		t.Fatalf("expected the trace to end with the program, got %v", events)
+	}
+	found := false
+	for _, event := range events {
+		found = found || event == "Parsing return statement: counts"
+	}
+	if !found {
+		t.Fatalf("expected a trace event for the return statement, got %v", events)
+	}
+}
+
+func TestParse_SilentByDefault(t *testing.T) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	p := New(lexer.New(`pragma: "1.0.0";
+	class contract Counter {
+		pub func get(id: uint64): uint64 {
+			return id;
+		}
+	}
+	`))
+	p.ParseProgram()
+
+	w.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(r)
+	if len(out) != 0 {
+		t.Fatalf("expected no output without a tracer, got %q", out)
+	}
+}
+
+func TestParse_ErrorPositions(t *testing.T) {
+	p := New(lexer.New(`pragma: "1.0.0";
+class contract Counter {
+    pub func get(id uint64): uint64 {
+        return id;
+    }
+}
+`))
+	p.ParseProgram()
+
+	errs := p.SyntaxErrors()
+	if len(errs) == 0 {
+		t.Fatalf("expected syntax errors")
+	}
+	if errs[0].Line != 3 || errs[0].Column != 21 {
+		t.Fatalf("expected the first error at 3:21, got %d:%d: %s", errs[0].Line, errs[0].Column, errs[0].Message)
+	}
+	if !strings.HasPrefix(p.Errors()[0], "3:21: ") {
+		t.Fatalf("expected Errors to be prefixed with the position, got %q", p.Errors()[0])
+	}
+}
+
+func TestParse_Comments(t *testing.T) {
+	l := lexer.New(`pragma: "1.0.0";
+class contract Counter {
+    // reads a counter
+    pub func get(id: uint64): uint64 { // inline
+        // first
+        // second
+        return id;
+    }
+}
+`)
+	p := New(l)
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors())
+	}
+
+	fn := program.Statements[1].(*ast.ClassStatement).Body[0].(*ast.FuncStatement)
+	if len(fn.Body) != 1 {
+		t.Fatalf("expected comments to be skipped, got %d statements", len(fn.Body))
+	}
+	if got := len(l.Comments()); got != 4 {
+		t.Fatalf("expected the lexer to keep 4 comments, got %d", got)
+	}
+	if c := l.Comments()[1]; c.Literal != "// inline" || c.Line != 4 || c.Column != 40 {
+		t.Fatalf("unexpected comment %+v", c)
+	}
+}
+
+func TestParse_AttachComments(t *testing.T) {
+	l := lexer.New(`// license
+pragma: "1.0.0";
+
+/* The counter
+   contract */
+class contract Counter {
+    uint64 total: 0; // running total
+
+    // reads a counter
+    pub func get(id: uint64): uint64 { // inline
+        /* first */ return id;
+        // end of get
+    } // after get
+}
+`, lexer.WithComments())
+	p := New(l)
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors())
+	}
+
+	class := program.Statements[1].(*ast.ClassStatement)
+	total := class.Body[0]
+	fn := class.Body[1].(*ast.FuncStatement)
+	if len(fn.Body) != 1 {
+		t.Fatalf("expected comments to be skipped, got %d statements", len(fn.Body))
+	}
+
+	tests := []struct {
+		node     ast.Node
+		leading  []string
+		trailing []string
+	}{
+		{program.Statements[0], []string{"// license"}, nil},
+		{class, []string{"/* The counter\n   contract */"}, nil},
+		{total, nil, []string{"// running total"}},
+		{fn, []string{"// reads a counter"}, []string{"// inline", "// after get"}},
+		{fn.Body[0], []string{"/* first */"}, []string{"// end of get"}},
+	}
+	for _, tt := range tests {
+		group := program.Comments[tt.node]
+		if group == nil {
+			t.Fatalf("no comments attached to %s", tt.node.String())
+		}
+		if got := literals(group.Leading); !reflect.DeepEqual(got, tt.leading) {
+			t.Errorf("%s: expected leading %q, got %q", tt.node.TokenLiteral(), tt.leading, got)
+		}
+		if got := literals(group.Trailing); !reflect.DeepEqual(got, tt.trailing) {
+			t.Errorf("%s: expected trailing %q, got %q", tt.node.TokenLiteral(), tt.trailing, got)
+		}
+	}
+}
+
+func TestParse_CommentsOffByDefault(t *testing.T) {
+	l := lexer.New(`pragma: "1.0.0"; /* note */`)
+	p := New(l)
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors())
+	}
+	if program.Comments != nil {
+		t.Fatalf("expected no attached comments without lexer.WithComments, got %v", program.Comments)
+	}
+	if got := l.Comments(); len(got) != 1 || got[0].Literal != "/* note */" {
+		t.Fatalf("expected the lexer to keep the block comment, got %v", got)
+	}
+}
+
+func TestParse_UnterminatedBlockComment(t *testing.T) {
+	p := New(lexer.New("pragma: \"1.0.0\";\n/* never closed\nclass contract A {}"))
+	p.ParseProgram()
+	errs := p.Errors()
+	if len(errs) != 1 || errs[0] != `2:1: illegal token "/*"` {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func literals(tokens []token.Token) []string {
+	var out []string
+	for _, tok := range tokens {
+		out = append(out, tok.Literal)
+	}
+	return out
+}
+
+func TestParse_Mutability(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Counter {
+		pub func double(a: uint64) pure: uint64 {
+			return a + a;
+		}
+		pub func current() view onlyOwner override: uint64 {
+			return count();
+		}
+		pub func increment(): void {
+			count(): count() + 1;
+		}
+		pub func deposit() payable: void {
+			count(): count() + value();
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	body := program.Statements[1].(*ast.ClassStatement).Body
+	for i, want := range []string{"pure", "view", "", "payable"} {
+		if fn := body[i].(*ast.FuncStatement); fn.Mutability != want {
+			t.Fatalf("%s: expected mutability %q, got %q", fn.Name, want, fn.Mutability)
+		}
+	}
+	current := body[1].(*ast.FuncStatement)
+	if len(current.Modifiers) != 1 || !current.Override {
+		t.Fatalf("expected the modifiers and override after the mutability, got %+v", current)
+	}
+}
+
+func TestParse_ConstAndImmutable(t *testing.T) {
+	input := `pragma: "1.0.0";
+	class contract Fees {
+		pub const uint64 rate: 3;
+		immutable address owner;
+		uint64 collected;
+		constructor(admin: address) {
+			owner: admin;
+		}
+	}
+	`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram().(*ast.Program)
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parser errors: %v", p.Errors())
+	}
+
+	body := program.Statements[1].(*ast.ClassStatement).Body
+	rate, ok := body[0].(*ast.VariableStatement)
+	if !ok || rate.Kind != ast.KindConst || !rate.Public || rate.Token.Type != token.UINT64 || rate.String() != "const uint64 rate: 3" {
+		t.Fatalf("unexpected constant %+v", body[0])
+	}
+	owner, ok := body[1].(*ast.VariableStatementNonInitializer)
+	if !ok || owner.Kind != ast.KindImmutable || owner.Public {
+		t.Fatalf("unexpected immutable %+v", body[1])
+	}
+	if collected := body[2].(*ast.VariableStatementNonInitializer); collected.Kind != "" {
+		t.Fatalf("expected a storage variable, got kind %q", collected.Kind)
+	}
+
+	assign, ok := body[3].(*ast.ConstructorStatement).Body[0].(*ast.AssignStatement)
+	if !ok || assign.Name != "owner" || assign.Value.String() != "admin" || assign.Token.Line != 7 {
+		t.Fatalf("unexpected assignment %+v", body[3].(*ast.ConstructorStatement).Body[0])
+	}
+
+	p = New(lexer.New(`pragma: "1.0.0";
+	class contract Fees {
+		const rate: 3;
+	}`))
+	p.ParseProgram()
+	if errs := p.Errors(); len(errs) == 0 || !strings.Contains(errs[0], "expected a type after const") {
+		t.Fatalf("expected a missing type error, got %v", errs)
+	}
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"github.com/polarysfoundation/ryot/ast"
+	"github.com/polarysfoundation/ryot/token"
+)
+
+// ResolveMemberCalls turns the calls parsed as builtins, such as transfer(to, 1),
+// into member calls when the class declares or inherits a function or storage
+// with that name. Members take precedence over builtins and are called through
+// a StorageAccessStatement like any other member. Only the parents declared in
+// program are considered, so the compiler runs it again once the imported
+// classes are merged into the program.
+func ResolveMemberCalls(program *ast.Program) {
+	classes := make(map[string]*ast.ClassStatement)
+	for _, stmt := range program.Statements {
+		if class, ok := stmt.(*ast.ClassStatement); ok {
+			classes[class.Name] = class
+		}
+	}
+
+	for _, class := range classes {
+		r := &memberCalls{members: make(map[string]bool)}
+		r.collect(class, classes, make(map[string]bool))
+		for _, stmt := range class.Body {
+			r.node(stmt)
+		}
+	}
+}
+
+// memberCalls rewrites the builtin calls of a class that name one of its members
+type memberCalls struct {
+	members map[string]bool
+}
+
+// collect adds the functions and storages of class and of its ancestors to the member set
+func (r *memberCalls) collect(class *ast.ClassStatement, classes map[string]*ast.ClassStatement, seen map[string]bool) {
+	if seen[class.Name] {
+		return // inheritance cycles are reported by the code generator
+	}
+	seen[class.Name] = true
+
+	for _, stmt := range class.Body {
+		switch n := stmt.(type) {
+		case *ast.FuncStatement:
+			r.members[n.Name] = true
+		case *ast.StorageDeclaration:
+			r.members[n.Name] = true
+		}
+	}
+	for _, parent := range class.Parents {
+		if p, ok := classes[parent]; ok {
+			r.collect(p, classes, seen)
+		}
+	}
+}
+
+// expr resolves the calls inside e and returns the node that replaces it
+func (r *memberCalls) expr(e ast.Expression) ast.Expression {
+	if e == nil {
+		return nil
+	}
+	r.node(e)
+	if call, ok := e.(*ast.CallExpression); ok && r.members[call.Function.String()] {
+		return &ast.StorageAccessStatement{
+			Token:  token.Token{Type: token.STORAGE, Literal: "storage"},
+			Name:   call.Function.String(),
+			Params: call.Arguments,
+		}
+	}
+	return e
+}
+
+// exprs resolves every expression of list in place
+func (r *memberCalls) exprs(list []ast.Expression) {
+	for i := range list {
+		list[i] = r.expr(list[i])
+	}
+}
+
+// node resolves the calls held by the fields of n
+func (r *memberCalls) node(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.FuncStatement:
+		for i := range n.Modifiers {
+			r.exprs(n.Modifiers[i].Arguments)
+		}
+		for _, stmt := range n.Body {
+			r.node(stmt)
+		}
+	case *ast.ModifierStatement:
+		for _, stmt := range n.Body {
+			r.node(stmt)
+		}
+	case *ast.ConstructorStatement:
+		for _, stmt := range n.Body {
+			r.node(stmt)
+		}
+	case *ast.VariableStatement:
+		n.Value = r.expr(n.Value)
+	case *ast.AssignStatement:
+		n.Value = r.expr(n.Value)
+	case *ast.ExpressionStatement:
+		n.Expression = r.expr(n.Expression)
+	case *ast.ReturnStatement:
+		n.Value = r.expr(n.Value)
+	case *ast.RevertStatement:
+		if n.Error != nil {
+			r.exprs(n.Error.Arguments)
+		}
+	case *ast.NewStatement:
+		n.Value = r.expr(n.Value)
+	case *ast.StorageStatement:
+		r.exprs(n.Params)
+		n.Value = r.expr(n.Value)
+	case *ast.StorageAccessStatement:
+		r.exprs(n.Params)
+	case *ast.ConstExpression:
+		n.Value = r.expr(n.Value)
+	case *ast.ErrLiteral:
+		n.Value = r.expr(n.Value)
+		n.Return = r.expr(n.Return)
+	case *ast.ErrValue:
+		n.Value = r.expr(n.Value)
+	case *ast.ErrorCallExpression:
+		r.exprs(n.Arguments)
+	case *ast.BinaryExpression:
+		n.Left = r.expr(n.Left)
+		n.Right = r.expr(n.Right)
+	case *ast.CallExpression:
+		r.exprs(n.Arguments)
+	case *ast.MemberExpression:
+		n.Object = r.expr(n.Object)
+	case *ast.ExternalCallExpression:
+		n.Address = r.expr(n.Address)
+		r.exprs(n.Arguments)
+	case *ast.CreateExpression:
+		r.exprs(n.Arguments)
+	case *ast.ArrayLiteral:
+		r.exprs(n.Elements)
+	}
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+)
+
+// Tracer receives debug events from the parser. Parsers are silent unless a
+// tracer is configured with WithTracer.
+type Tracer interface {
+	Tracef(format string, args ...interface{})
+}
+
+// TracerFunc adapts an ordinary function to the Tracer interface.
+type TracerFunc func(format string, args ...interface{})
+
+// Tracef calls f(format, args...).
+func (f TracerFunc) Tracef(format string, args ...interface{}) {
+	f(format, args...)
+}
+
+// NewWriterTracer returns a Tracer that writes one line per event to w, e.g.
+// parser.New(l, parser.WithTracer(parser.NewWriterTracer(os.Stderr))) to debug the grammar.
+func NewWriterTracer(w io.Writer) Tracer {
+	return TracerFunc(func(format string, args ...interface{}) {
+		fmt.Fprintf(w, format+"\n", args...)
+	})
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithTracer enables the parse trace mode, sending every trace event to t.
+func WithTracer(t Tracer) Option {
+	return func(p *Parser) {
+		p.tracer = t
+	}
+}
+
+// tracef sends a trace event to the configured tracer, if any
+func (p *Parser) tracef(format string, args ...interface{}) {
+	if p.tracer != nil {
+		p.tracer.Tracef(format, args...)
+	}
+}
@@ -1,16 +1,24 @@
 package token
 
+import (
+	"sort"
+	"unicode"
+)
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int // Línea del primer carácter del token, empezando en 1.
+	Column  int // Columna del primer carácter del token, empezando en 1.
 }
 
 const (
 	// Especiales
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // Comentario // o /* */; NextToken solo lo devuelve con lexer.WithComments.
 
 	// Identificadores + literales
 	IDENT           = "IDENT"
@@ -44,6 +52,7 @@ const (
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
 	LPAREN    = "("
 	RPAREN    = ")"
 	LBRACE    = "{"
@@ -52,22 +61,36 @@ const (
 	RBRACKET  = "]"
 
 	// Palabras clave
-	CLASS     = "CLASS"
-	STRUCT    = "STRUCT"
-	ENUM      = "ENUM"
-	PRAGMA    = "PRAGMA"
-	PUB       = "PUB"
-	PRIV      = "PRIV"
-	STORAGE   = "STORAGE"
-	FUNC      = "FUNC"
-	DELETE    = "DELETE"
-	RETURN    = "RETURN"
-	NEW       = "NEW"
-	CONTRACT  = "CONTRACT"
-	INTERFACE = "INTERFACE"
-	VOID      = "VOID"
-	CHECK     = "CHECK"
-	ERR       = "ERR"
+	CLASS       = "CLASS"
+	STRUCT      = "STRUCT"
+	ENUM        = "ENUM"
+	PRAGMA      = "PRAGMA"
+	IMPORT      = "IMPORT"
+	FROM        = "FROM"
+	PUB         = "PUB"
+	PRIV        = "PRIV"
+	STORAGE     = "STORAGE"
+	FUNC        = "FUNC"
+	CONSTRUCTOR = "CONSTRUCTOR"
+	MODIFIER    = "MODIFIER"
+	IS          = "IS"
+	OVERRIDE    = "OVERRIDE"
+	VIEW        = "VIEW"
+	PURE        = "PURE"
+	PAYABLE     = "PAYABLE"
+	CONST       = "CONST"
+	IMMUTABLE   = "IMMUTABLE"
+	DELETE      = "DELETE"
+	RETURN      = "RETURN"
+	NEW         = "NEW"
+	CREATE      = "CREATE"
+	CONTRACT    = "CONTRACT"
+	INTERFACE   = "INTERFACE"
+	VOID        = "VOID"
+	CHECK       = "CHECK"
+	ERR         = "ERR"
+	ERROR       = "ERROR"
+	REVERT      = "REVERT"
 
 	// Types
 	UINT64  = "UINT64"
@@ -80,20 +103,25 @@ const (
 )
 
 var keywords = map[string]TokenType{
-	"class":     CLASS,
-	"struct":    STRUCT,
-	"enum":      ENUM,
-	"pragma":    PRAGMA,
-	"pub":       PUB,
-	"priv":      PRIV,
-	"storage":   STORAGE,
-	"func":      FUNC,
-	"delete":    DELETE,
-	"return":    RETURN,
-	"new":       NEW,
-	"contract":  CONTRACT,
-	"interface": INTERFACE,
-	"void":      VOID,
+	"class":       CLASS,
+	"struct":      STRUCT,
+	"enum":        ENUM,
+	"pragma":      PRAGMA,
+	"import":      IMPORT,
+	"pub":         PUB,
+	"priv":        PRIV,
+	"storage":     STORAGE,
+	"func":        FUNC,
+	"constructor": CONSTRUCTOR,
+	"modifier":    MODIFIER,
+	"override":    OVERRIDE,
+	"payable":     PAYABLE,
+	"delete":      DELETE,
+	"return":      RETURN,
+	"new":         NEW,
+	"contract":    CONTRACT,
+	"interface":   INTERFACE,
+	"void":        VOID,
 
 	// Types
 	"uint64":  UINT64,
@@ -118,6 +146,33 @@ var keywords = map[string]TokenType{
 	"||": OR,
 }
 
+// contextual contiene las palabras clave contextuales: el lexer las devuelve
+// como IDENT y solo actúan como palabra clave donde la gramática las espera, de
+// modo que siguen sirviendo como nombres, por ejemplo transfer(from: address).
+var contextual = map[string]TokenType{
+	"from":      FROM,
+	"is":        IS,
+	"view":      VIEW,
+	"pure":      PURE,
+	"const":     CONST,
+	"immutable": IMMUTABLE,
+	"create":    CREATE,
+	"error":     ERROR,
+	"revert":    REVERT,
+}
+
+// builtins son las funciones predefinidas del lenguaje; se llaman como name(args).
+// codegen define la firma de cada una y el opcode al que se compila.
+var builtins = map[string]bool{
+	"caller":       true,
+	"self":         true,
+	"balanceOf":    true,
+	"pm256":        true,
+	"selfdestruct": true,
+	"value":        true,
+	"transfer":     true,
+}
+
 func (t TokenType) String() string {
 	return string(t)
 }
@@ -126,6 +181,48 @@ func (t Token) String() string {
 	return "Token(" + t.Type.String() + ", " + t.Literal + ")"
 }
 
+// Is reports whether tok is of type t. Contextual keywords are lexed as IDENT,
+// so an identifier also matches t when its literal is the contextual keyword t.
+func (tok Token) Is(t TokenType) bool {
+	return tok.Type == t || tok.Type == IDENT && contextual[tok.Literal] == t
+}
+
+// Keywords returns the keywords of the language, reserved and contextual, in alphabetical order
+func Keywords() []string {
+	words := make([]string, 0, len(keywords)+len(contextual))
+	for word := range keywords {
+		if unicode.IsLetter(rune(word[0])) {
+			words = append(words, word)
+		}
+	}
+	for word := range contextual {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// IsBuiltin reports whether ident names a builtin function
+func IsBuiltin(ident string) bool {
+	return builtins[ident]
+}
+
+// Builtins returns the names of the builtin functions in alphabetical order
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsReserved reports whether ident is a reserved word that cannot name anything
+func IsReserved(ident string) bool {
+	_, ok := keywords[ident]
+	return ok
+}
+
 func LookupIdent(ident string) TokenType {
 	if tok, ok := keywords[ident]; ok {
 		return tok
@@ -0,0 +1,208 @@
+// Package version contiene la versión del compilador de Ryot y la comprobación
+// de los rangos semver que los programas declaran en su pragma.
+//
+// Un rango es una lista de comparaciones separadas por espacios que deben
+// cumplirse todas (">=1.0.0 <2.0.0"), o varias de esas listas separadas por "||"
+// de las que basta con que se cumpla una. Se admiten los operadores =, >, >=, <,
+// <=, ^ y ~; una versión sin operador exige esa versión exacta.
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Versión del compilador. El formato RYBC usa Major y Minor en su cabecera.
+const (
+	Major = 1
+	Minor = 0
+	Patch = 0
+)
+
+// Compiler es la versión del compilador.
+var Compiler = Version{Major: Major, Minor: Minor, Patch: Patch}
+
+// Version es una versión semántica major.minor.patch.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// Parse analiza una versión de la forma major.minor.patch.
+func Parse(s string) (Version, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("version: '%s' no es una versión major.minor.patch", s)
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part == "" || (len(part) > 1 && part[0] == '0') {
+			return Version{}, fmt.Errorf("version: '%s' no es una versión major.minor.patch", s)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare devuelve -1, 0 o 1 si v es menor, igual o mayor que o.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return sign(v.Major - o.Major)
+	case v.Minor != o.Minor:
+		return sign(v.Minor - o.Minor)
+	default:
+		return sign(v.Patch - o.Patch)
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// comparison es una comparación simple, p. ej. ">=1.0.0".
+type comparison struct {
+	op      string
+	version Version
+}
+
+func (c comparison) matches(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default: // "<="
+		return cmp <= 0
+	}
+}
+
+// Constraint es un rango de versiones ya analizado.
+type Constraint struct {
+	source string
+	sets   [][]comparison // Se cumple si se cumplen todas las comparaciones de algún conjunto.
+}
+
+// ParseConstraint analiza un rango de versiones como el del pragma.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{source: s}
+	for _, alternative := range strings.Split(s, "||") {
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			return Constraint{}, fmt.Errorf("version: rango vacío en '%s'", s)
+		}
+		var set []comparison
+		for _, field := range fields {
+			comparisons, err := parseComparison(field)
+			if err != nil {
+				return Constraint{}, err
+			}
+			set = append(set, comparisons...)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// parseComparison analiza un término del rango. ^ y ~ se expanden en dos comparaciones.
+func parseComparison(field string) ([]comparison, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(field, candidate) {
+			op = candidate
+			break
+		}
+	}
+	v, err := Parse(strings.TrimPrefix(field, op))
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "=":
+		return []comparison{{"=", v}}, nil
+	case "^":
+		// Admite los cambios que no modifican el primer número distinto de cero.
+		upper := Version{Major: v.Major + 1}
+		if v.Major == 0 && v.Minor > 0 {
+			upper = Version{Minor: v.Minor + 1}
+		} else if v.Major == 0 {
+			upper = Version{Patch: v.Patch + 1}
+		}
+		return []comparison{{">=", v}, {"<", upper}}, nil
+	case "~":
+		return []comparison{{">=", v}, {"<", Version{Major: v.Major, Minor: v.Minor + 1}}}, nil
+	default:
+		return []comparison{{op, v}}, nil
+	}
+}
+
+// Check indica si v está dentro del rango.
+func (c Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if checkSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Minimum devuelve la menor versión que cumple el rango, si existe alguna.
+func (c Constraint) Minimum() (Version, bool) {
+	var best Version
+	found := false
+	for _, set := range c.sets {
+		var low Version
+		for _, cmp := range set {
+			candidate := cmp.version
+			switch cmp.op {
+			case ">":
+				candidate.Patch++
+			case "<", "<=":
+				continue
+			}
+			if candidate.Compare(low) > 0 {
+				low = candidate
+			}
+		}
+		if !checkSet(set, low) {
+			continue
+		}
+		if !found || low.Compare(best) < 0 {
+			best, found = low, true
+		}
+	}
+	return best, found
+}
+
+// checkSet indica si v cumple todas las comparaciones de set.
+func checkSet(set []comparison, v Version) bool {
+	for _, cmp := range set {
+		if !cmp.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Constraint) String() string {
+	return c.source
+}
@@ -0,0 +1,84 @@
+package version
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	v, err := Parse("1.20.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != (Version{Major: 1, Minor: 20, Patch: 3}) || v.String() != "1.20.3" {
+		t.Fatalf("unexpected version %v", v)
+	}
+	for _, s := range []string{"", "1.0", "1.0.0.0", "1.x.0", "01.0.0", "-1.0.0"} {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		reject     []string
+		minimum    string
+	}{
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.2"}, "1.2.3"},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}, "1.2.3"},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}, "0.2.3"},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}, "0.0.3"},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0"}, "1.2.3"},
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.99.99"}, []string{"0.9.9", "2.0.0"}, "1.0.0"},
+		{">1.0.0", []string{"1.0.1"}, []string{"1.0.0"}, "1.0.1"},
+		{"<=1.0.0", []string{"0.0.0", "1.0.0"}, []string{"1.0.1"}, "0.0.0"},
+		{"^3.0.0 || ~1.1.0", []string{"3.1.0", "1.1.5"}, []string{"2.0.0", "1.2.0"}, "1.1.0"},
+		{">2.0.0 <1.0.0", nil, []string{"1.5.0", "3.0.0"}, ""},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.constraint, err)
+		}
+		for _, s := range tt.match {
+			if !c.Check(mustParse(t, s)) {
+				t.Fatalf("%s: expected %s to match", tt.constraint, s)
+			}
+		}
+		for _, s := range tt.reject {
+			if c.Check(mustParse(t, s)) {
+				t.Fatalf("%s: expected %s not to match", tt.constraint, s)
+			}
+		}
+		minimum, ok := c.Minimum()
+		if tt.minimum == "" {
+			if ok {
+				t.Fatalf("%s: expected no minimum, got %s", tt.constraint, minimum)
+			}
+		} else if !ok || minimum.String() != tt.minimum {
+			t.Fatalf("%s: expected minimum %s, got %s", tt.constraint, tt.minimum, minimum)
+		}
+	}
+
+	for _, s := range []string{"", "latest", ">=1.0", "1.0.0 ||", "^^1.0.0"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Fatalf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestCompilerVersion(t *testing.T) {
+	if Compiler.Major != Major || Compiler.Minor != Minor || Compiler.Patch != Patch {
+		t.Fatalf("Compiler %v does not match the version constants", Compiler)
+	}
+}
+
+func mustParse(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}